@@ -0,0 +1,259 @@
+// Package bench runs recall and throughput benchmarks against SIFT/GloVe style
+// datasets stored as fvecs/ivecs files
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	hnsw "github.com/jnmly/go-hnsw"
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/f32"
+	hnswio "github.com/jnmly/go-hnsw/io"
+)
+
+// Config describes a benchmark run. Base and Query are fvecs files,
+// GroundTruth is an optional ivecs file holding the indices of the true
+// nearest neighbours of every query. If GroundTruth is empty it is
+// computed by brute force.
+type Config struct {
+	Base        string
+	Query       string
+	GroundTruth string
+
+	M              []uint64
+	EfConstruction uint64
+	Ef             []uint64
+	K              uint64
+}
+
+// Result holds the measurements for one (M, ef) combination
+type Result struct {
+	M              uint64        `json:"m"`
+	EfConstruction uint64        `json:"ef_construction"`
+	Ef             uint64        `json:"ef"`
+	K              uint64        `json:"k"`
+	Recall         float64       `json:"recall"`
+	QPS            float64       `json:"qps"`
+	P50            time.Duration `json:"p50_ns"`
+	P99            time.Duration `json:"p99_ns"`
+	BuildTime      time.Duration `json:"build_time_ns"`
+	Memory         uint64        `json:"memory_bytes"`
+}
+
+// Run loads the dataset and sweeps every combination of cfg.M and cfg.Ef.
+// The index is built once per M value and queried once per ef value.
+func Run(cfg Config) ([]Result, error) {
+	if len(cfg.M) == 0 || len(cfg.Ef) == 0 || cfg.K == 0 {
+		return nil, errors.New("bench: M, Ef and K must be set")
+	}
+
+	base, err := readFvecs(cfg.Base)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := readFvecs(cfg.Query)
+	if err != nil {
+		return nil, err
+	}
+	if len(base) == 0 || len(queries) == 0 {
+		return nil, errors.New("bench: empty dataset")
+	}
+
+	var truth [][]int32
+	if cfg.GroundTruth != "" {
+		truth, err = readIvecs(cfg.GroundTruth)
+		if err != nil {
+			return nil, err
+		}
+		if len(truth) < len(queries) {
+			return nil, fmt.Errorf("bench: ground truth has %d entries for %d queries", len(truth), len(queries))
+		}
+	} else {
+		truth = bruteForce(base, queries, cfg.K)
+	}
+
+	results := make([]Result, 0, len(cfg.M)*len(cfg.Ef))
+	for _, m := range cfg.M {
+		h, buildTime, memory, err := build(base, m, cfg.EfConstruction)
+		if err != nil {
+			return nil, err
+		}
+		for _, ef := range cfg.Ef {
+			r := query(h, queries, truth, ef, cfg.K)
+			r.M = m
+			r.EfConstruction = cfg.EfConstruction
+			r.BuildTime = buildTime
+			r.Memory = memory
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func build(base [][]float32, M uint64, efConstruction uint64) (*hnsw.Hnsw, time.Duration, uint64, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	h, err := hnsw.NewEmpty(M, efConstruction, uint64(len(base[0])), hnsw.L2)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, v := range base {
+		if _, err := h.TryAdd(v); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	buildTime := time.Since(start)
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	memory := uint64(0)
+	if after.HeapAlloc > before.HeapAlloc {
+		memory = after.HeapAlloc - before.HeapAlloc
+	}
+	return h, buildTime, memory, nil
+}
+
+func query(h *hnsw.Hnsw, queries [][]float32, truth [][]int32, ef uint64, K uint64) Result {
+	latencies := make([]time.Duration, len(queries))
+	hits := 0
+
+	start := time.Now()
+	for i, q := range queries {
+		t0 := time.Now()
+		result := h.Search(q, ef, K)
+		latencies[i] = time.Since(t0)
+
+		// base vector j is node j
+		expected := make(map[uint64]bool, K)
+		for j := 0; j < int(K) && j < len(truth[i]); j++ {
+			expected[uint64(truth[i][j])] = true
+		}
+		for _, id := range ids(result) {
+			if expected[id] {
+				hits++
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	return Result{
+		Ef:     ef,
+		K:      K,
+		Recall: float64(hits) / float64(uint64(len(queries))*K),
+		QPS:    float64(len(queries)) / elapsed.Seconds(),
+		P50:    percentile(latencies, 0.50),
+		P99:    percentile(latencies, 0.99),
+	}
+}
+
+// ids returns the node ids of a search result, closest first
+func ids(result *distqueue.DistQueue) []uint64 {
+	ret := make([]uint64, result.Len())
+	for i := len(ret) - 1; i >= 0; i-- {
+		ret[i] = result.Pop().Node
+	}
+	return ret
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p * float64(len(sorted)-1))
+	return sorted[i]
+}
+
+func bruteForce(base [][]float32, queries [][]float32, K uint64) [][]int32 {
+	truth := make([][]int32, len(queries))
+	for i, q := range queries {
+		resultSet := &distqueue.DistQueue{Size: K, ClosestLast: true}
+		for j, v := range base {
+			d := f32.L2Squared(q, v)
+			if uint64(j) < K {
+				resultSet.Push(uint64(j), d)
+				continue
+			}
+			if _, topD := resultSet.Top(); d < topD {
+				resultSet.PopAndPush(uint64(j), d)
+			}
+		}
+		truth[i] = make([]int32, resultSet.Len())
+		for j := len(truth[i]) - 1; j >= 0; j-- {
+			truth[i][j] = int32(resultSet.Pop().Node)
+		}
+	}
+	return truth
+}
+
+func readFvecs(filename string) ([][]float32, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return hnswio.ReadAll(hnswio.NewFvecsReader(f))
+}
+
+func readIvecs(filename string) ([][]int32, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := hnswio.NewIvecsReader(f)
+	vecs := make([][]int32, 0)
+	for {
+		v, err := r.ReadInts()
+		if err == io.EOF {
+			return vecs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		vecs = append(vecs, v)
+	}
+}
+
+// WriteCSV writes the results with a header row
+func WriteCSV(w io.Writer, results []Result) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"m", "ef_construction", "ef", "k", "recall", "qps", "p50_ns", "p99_ns", "build_time_ns", "memory_bytes"})
+	for _, r := range results {
+		c.Write([]string{
+			strconv.FormatUint(r.M, 10),
+			strconv.FormatUint(r.EfConstruction, 10),
+			strconv.FormatUint(r.Ef, 10),
+			strconv.FormatUint(r.K, 10),
+			strconv.FormatFloat(r.Recall, 'f', 4, 64),
+			strconv.FormatFloat(r.QPS, 'f', 1, 64),
+			strconv.FormatInt(int64(r.P50), 10),
+			strconv.FormatInt(int64(r.P99), 10),
+			strconv.FormatInt(int64(r.BuildTime), 10),
+			strconv.FormatUint(r.Memory, 10),
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// WriteJSON writes the results as a JSON array
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFvecs(t *testing.T, filename string, vecs [][]float32) {
+	buf := &bytes.Buffer{}
+	for _, v := range vecs {
+		binary.Write(buf, binary.LittleEndian, int32(len(v)))
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	assert.NoError(t, ioutil.WriteFile(filename, buf.Bytes(), os.ModePerm))
+}
+
+func randomVecs(n int, dim int) [][]float32 {
+	vecs := make([][]float32, n)
+	for i := range vecs {
+		vecs[i] = make([]float32, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = rand.Float32()
+		}
+	}
+	return vecs
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bench")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "base.fvecs")
+	query := filepath.Join(dir, "query.fvecs")
+	writeFvecs(t, base, randomVecs(500, 16))
+	writeFvecs(t, query, randomVecs(20, 16))
+
+	results, err := Run(Config{
+		Base:           base,
+		Query:          query,
+		M:              []uint64{8, 16},
+		Ef:             []uint64{20, 100},
+		EfConstruction: 100,
+		K:              5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(results))
+	for _, r := range results {
+		t.Logf("M=%d ef=%d recall=%.3f qps=%.0f p50=%v p99=%v", r.M, r.Ef, r.Recall, r.QPS, r.P50, r.P99)
+		assert.True(t, r.Recall > 0.8)
+		assert.True(t, r.P50 <= r.P99)
+	}
+
+	csv := &bytes.Buffer{}
+	assert.NoError(t, WriteCSV(csv, results))
+	assert.Equal(t, 5, len(strings.Split(strings.TrimSpace(csv.String()), "\n")))
+
+	js := &bytes.Buffer{}
+	assert.NoError(t, WriteJSON(js, results))
+	var decoded []Result
+	assert.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, results, decoded)
+}
+
+func TestGroundTruthFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bench")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	vecs := randomVecs(200, 16)
+	queries := randomVecs(10, 16)
+	truth := bruteForce(vecs, queries, 3)
+
+	buf := &bytes.Buffer{}
+	for _, v := range truth {
+		binary.Write(buf, binary.LittleEndian, int32(len(v)))
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	gt := filepath.Join(dir, "gt.ivecs")
+	assert.NoError(t, ioutil.WriteFile(gt, buf.Bytes(), os.ModePerm))
+
+	read, err := readIvecs(gt)
+	assert.NoError(t, err)
+	assert.Equal(t, truth, read)
+}
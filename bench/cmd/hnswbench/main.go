@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jnmly/go-hnsw/bench"
+)
+
+func main() {
+	var (
+		base           = flag.String("base", "", "fvecs file with the points to index")
+		query          = flag.String("query", "", "fvecs file with the query points")
+		groundTruth    = flag.String("groundtruth", "", "ivecs file with the true nearest neighbours (optional)")
+		m              = flag.String("m", "16", "comma separated list of M values")
+		ef             = flag.String("ef", "10,20,50,100,200", "comma separated list of ef values")
+		efConstruction = flag.Uint64("efconstruction", 200, "efConstruction used when building the index")
+		k              = flag.Uint64("k", 10, "number of neighbours to search for")
+		format         = flag.String("format", "csv", "output format, csv or json")
+	)
+	flag.Parse()
+
+	cfg := bench.Config{
+		Base:           *base,
+		Query:          *query,
+		GroundTruth:    *groundTruth,
+		M:              parseList(*m),
+		Ef:             parseList(*ef),
+		EfConstruction: *efConstruction,
+		K:              *k,
+	}
+
+	results, err := bench.Run(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		err = bench.WriteJSON(os.Stdout, results)
+	default:
+		err = bench.WriteCSV(os.Stdout, results)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func parseList(s string) []uint64 {
+	ret := make([]uint64, 0)
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(f), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid value %q\n", f)
+			os.Exit(1)
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
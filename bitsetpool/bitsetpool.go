@@ -13,12 +13,12 @@ type poolItem struct {
 
 type BitsetPool struct {
 	sync.RWMutex
-	pool []poolItem
+	pool []*poolItem
 }
 
 func New() *BitsetPool {
 	var bp BitsetPool
-	bp.pool = make([]poolItem, 0)
+	bp.pool = make([]*poolItem, 0)
 	return &bp
 }
 
@@ -31,15 +31,25 @@ func (bp *BitsetPool) Free(i int) {
 func (bp *BitsetPool) Get() (int, *bitset.BitSet) {
 	bp.Lock()
 	for i := range bp.pool {
-		if !bp.pool[i].busy {
-			bp.pool[i].busy = true
-			bp.pool[i].b.ClearAll()
+		if item := bp.pool[i]; !item.busy {
+			item.busy = true
+			item.b.ClearAll()
 			bp.Unlock()
-			return i, &bp.pool[i].b
+			return i, &item.b
 		}
 	}
 	id := len(bp.pool)
-	bp.pool = append(bp.pool, poolItem{})
+	// items are allocated separately, growing the pool must not move
+	// bitsets which are in use
+	item := &poolItem{busy: true}
+	bp.pool = append(bp.pool, item)
 	bp.Unlock()
-	return id, &bp.pool[id].b
+	return id, &item.b
+}
+
+// Size returns the number of bitsets allocated by the pool
+func (bp *BitsetPool) Size() int {
+	bp.RLock()
+	defer bp.RUnlock()
+	return len(bp.pool)
 }
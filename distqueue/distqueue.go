@@ -77,6 +77,10 @@ func (pq *DistQueue) Head() (uint64, float32) {
 }
 
 func (pq *DistQueue) Len() uint64 {
+	if len(pq.items) == 0 {
+		// nothing pushed yet
+		return 0
+	}
 	return uint64(len(pq.items) - 1)
 }
 
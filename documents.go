@@ -0,0 +1,203 @@
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+var errDocumentKey = errors.New("hnsw: document key 0 is reserved for points without document")
+
+// documentIndex keeps the node ids of every document
+type documentIndex struct {
+	sync.RWMutex
+	nodes map[uint64][]uint64
+}
+
+func (d *documentIndex) add(document uint64, id uint64) {
+	if document == 0 {
+		return
+	}
+	d.Lock()
+	if d.nodes == nil {
+		d.nodes = make(map[uint64][]uint64)
+	}
+	d.nodes[document] = append(d.nodes[document], id)
+	d.Unlock()
+}
+
+func (d *documentIndex) remove(document uint64, id uint64) {
+	if document == 0 {
+		return
+	}
+	d.Lock()
+	ids := d.nodes[document]
+	for i, n := range ids {
+		if n == id {
+			ids[i] = ids[len(ids)-1]
+			ids = ids[:len(ids)-1]
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(d.nodes, document)
+	} else {
+		d.nodes[document] = ids
+	}
+	d.Unlock()
+}
+
+// Aggregation selects how SearchDocuments combines the similarities of the
+// points of a document. The similarity of a point at distance d is
+// 1 / (1 + d).
+type Aggregation int
+
+const (
+	// MaxSim scores a document by its most similar point
+	MaxSim Aggregation = iota
+	// MeanSim scores a document by the mean similarity of its top points
+	MeanSim
+	// SumSim scores a document by the summed similarity of its top points
+	SumSim
+)
+
+// DocumentOptions configures SearchDocuments
+type DocumentOptions struct {
+	Aggregation Aggregation
+	// TopN is the number of the most similar points per document used by
+	// MeanSim and SumSim, 0 uses all points found
+	TopN int
+	// Candidates is the number of points taken from Search before grouping,
+	// defaults to ef
+	Candidates uint64
+}
+
+// AddDocument adds points as the points of document key and returns their
+// node ids. The key must not be 0. If an insert fails the points added
+// before stay in the index.
+func (h *Hnsw) AddDocument(key uint64, points [][]float32) ([]uint64, error) {
+	return h.AddDocumentContext(context.Background(), key, points)
+}
+
+// AddDocumentContext is like AddDocument but gives up when ctx is done, see
+// AddContext
+func (h *Hnsw) AddDocumentContext(ctx context.Context, key uint64, points [][]float32) ([]uint64, error) {
+	if key == 0 {
+		return nil, errDocumentKey
+	}
+	ids := make([]uint64, 0, len(points))
+	for _, p := range points {
+		id, err := h.addContext(ctx, p, nil, key)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Document returns the key of the document node id belongs to, 0 if it
+// isn't part of one
+func (h *Hnsw) Document(id uint64) uint64 {
+	if n := h.node(id); n != nil {
+		return n.Document
+	}
+	return 0
+}
+
+// DocumentNodes returns the node ids of document key
+func (h *Hnsw) DocumentNodes(key uint64) []uint64 {
+	h.documents.RLock()
+	defer h.documents.RUnlock()
+	return append([]uint64(nil), h.documents.nodes[key]...)
+}
+
+// RemoveDocument removes every point of document key. It returns
+// ErrNotFound for unknown documents.
+func (h *Hnsw) RemoveDocument(key uint64) error {
+	start := time.Now()
+	h.lock()
+	defer h.Unlock()
+
+	ids := h.DocumentNodes(key)
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+	for _, id := range ids {
+		if h.wal != nil {
+			if err := h.wal.logRemove(id); err != nil {
+				return err
+			}
+		}
+		level := h.Nodes[id].Level
+		h.remove(id)
+		h.reportLevel(level)
+	}
+	h.metrics.ObserveRemove(time.Since(start))
+	return nil
+}
+
+func (h *Hnsw) SearchDocuments(q framework.Point, ef uint64, K uint64, opts DocumentOptions) *distqueue.DistQueue {
+	resultSet, _ := h.SearchDocumentsContext(context.Background(), q, ef, K, opts)
+	return resultSet
+}
+
+// SearchDocumentsContext groups the points found by a search by document
+// and returns the K best documents. Points without document are skipped.
+// The result is ordered like the one of Search, Pop returns the worst
+// document first, Node holds the document key and D the negated aggregated
+// similarity.
+func (h *Hnsw) SearchDocumentsContext(ctx context.Context, q framework.Point, ef uint64, K uint64, opts DocumentOptions) (*distqueue.DistQueue, error) {
+	n := opts.Candidates
+	if n == 0 {
+		n = ef
+	}
+	found, err := h.SearchContext(ctx, q, max(ef, n), n)
+
+	// the points are popped farthest first, so every document collects its
+	// similarities in ascending order
+	similarities := make(map[uint64][]float32)
+	for found.Len() > 0 {
+		item := found.Pop()
+		if document := h.Document(item.Node); document != 0 {
+			similarities[document] = append(similarities[document], 1/(1+item.D))
+		}
+	}
+
+	documents := make([]uint64, 0, len(similarities))
+	for document := range similarities {
+		documents = append(documents, document)
+	}
+	// push in key order so ties are resolved the same way every time
+	sort.Slice(documents, func(i, j int) bool { return documents[i] < documents[j] })
+
+	resultSet := &distqueue.DistQueue{Size: K + 1, ClosestLast: true}
+	for _, document := range documents {
+		sims := similarities[document]
+		if opts.TopN > 0 && len(sims) > opts.TopN {
+			sims = sims[len(sims)-opts.TopN:]
+		}
+		var score float32
+		switch opts.Aggregation {
+		case MeanSim, SumSim:
+			for _, s := range sims {
+				score += s
+			}
+			if opts.Aggregation == MeanSim {
+				score /= float32(len(sims))
+			}
+		default:
+			score = sims[len(sims)-1]
+		}
+		resultSet.Push(document, -score)
+		if resultSet.Len() > K {
+			resultSet.Pop()
+		}
+	}
+	return resultSet, err
+}
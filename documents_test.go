@@ -0,0 +1,92 @@
+package hnsw
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocuments(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	// 50 documents of 4 points each, and 100 points without document
+	for key := uint64(1); key <= 50; key++ {
+		ids, err := h.AddDocument(key, vecs[4*(key-1):4*key])
+		assert.NoError(t, err)
+		assert.Len(t, ids, 4)
+	}
+	for _, v := range vecs[200:300] {
+		h.Add(v)
+	}
+	_, err := h.AddDocument(0, vecs[:1])
+	assert.Error(t, err)
+
+	documents := func(res *distqueue.DistQueue) []uint64 {
+		keys := make([]uint64, res.Len())
+		for i := len(keys) - 1; i >= 0; i-- {
+			keys[i] = res.Pop().Node
+		}
+		return keys
+	}
+
+	// the best document by MaxSim holds the closest point with a document
+	var closest uint64
+	for res := documents(h.Search(q, 300, 300)); closest == 0; res = res[1:] {
+		closest = h.Document(res[0])
+	}
+	keys := documents(h.SearchDocuments(q, 300, 5, DocumentOptions{Candidates: 300}))
+	assert.Len(t, keys, 5)
+	assert.Equal(t, closest, keys[0])
+
+	for _, agg := range []Aggregation{MeanSim, SumSim} {
+		keys := documents(h.SearchDocuments(q, 300, 5, DocumentOptions{Aggregation: agg, TopN: 2, Candidates: 300}))
+		assert.Len(t, keys, 5)
+		seen := make(map[uint64]bool)
+		for _, k := range keys {
+			assert.True(t, k >= 1 && k <= 50)
+			assert.False(t, seen[k])
+			seen[k] = true
+		}
+	}
+
+	h.RemoveDocument(closest)
+	assert.Empty(t, h.DocumentNodes(closest))
+	assert.NotContains(t, documents(h.SearchDocuments(q, 300, 50, DocumentOptions{Candidates: 300})), closest)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.Save(buf))
+	g, err := Load(buf)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, h.DocumentNodes(1), g.DocumentNodes(1))
+	assert.Len(t, g.DocumentNodes(2), 4)
+}
+
+// TestDocumentsLocking removes documents while they are searched, run it
+// with -race
+func TestDocumentsLocking(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for key := uint64(1); key <= 100; key++ {
+		_, err := h.AddDocument(key, vecs[4*(key-1):4*key])
+		assert.NoError(t, err)
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		for key := uint64(1); key <= 50; key++ {
+			assert.NoError(t, h.RemoveDocument(key))
+		}
+		wg.Done()
+	}()
+	go func() {
+		for i := 0; i < 50; i++ {
+			h.SearchDocuments(q, 100, 10, DocumentOptions{})
+		}
+		wg.Done()
+	}()
+	wg.Wait()
+}
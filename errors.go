@@ -0,0 +1,82 @@
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+var (
+	// ErrDimensionMismatch is returned for points whose dimension differs
+	// from the one of the index
+	ErrDimensionMismatch = errors.New("hnsw: dimension mismatch")
+	// ErrNotFound is returned for node ids which aren't in the index
+	ErrNotFound = errors.New("hnsw: node not found")
+	// ErrEmptyIndex is returned by searches of an index without nodes
+	ErrEmptyIndex = errors.New("hnsw: empty index")
+	// ErrNaN is returned for points with NaN or infinite coordinates
+	ErrNaN = errors.New("hnsw: point contains NaN or infinite values")
+	// ErrZeroVector is returned for zero vectors added to or searched in a
+	// normalised index
+	ErrZeroVector = errors.New("hnsw: can't normalise a zero vector")
+	// ErrIncompatible is returned by Merge for indexes which transform or
+	// normalise points differently
+	ErrIncompatible = errors.New("hnsw: incompatible indexes")
+)
+
+// validate checks q against the dimension expected by the index, before
+// any transform is applied
+func (h *Hnsw) validate(q framework.Point) error {
+	for _, x := range q {
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			return ErrNaN
+		}
+	}
+	dim := h.Dimension
+	if h.Transform != nil {
+		dim = h.Transform.InputDim
+	}
+	if dim != 0 && uint64(len(q)) != dim {
+		return fmt.Errorf("%w: point has dimension %d, the index %d", ErrDimensionMismatch, len(q), dim)
+	}
+	return nil
+}
+
+// TryAdd is like Add but returns an error instead of panicking on invalid
+// points
+func (h *Hnsw) TryAdd(q framework.Point) (uint64, error) {
+	return h.AddContext(context.Background(), q)
+}
+
+// TryRemove is like Remove but returns ErrNotFound for unknown ids and the
+// errors of the write-ahead log
+func (h *Hnsw) TryRemove(id uint64) error {
+	start := time.Now()
+	h.lock()
+	defer h.Unlock()
+
+	n, ok := h.Nodes[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if h.wal != nil {
+		if err := h.wal.logRemove(id); err != nil {
+			return err
+		}
+	}
+	h.remove(id)
+	h.reportLevel(n.Level)
+	h.metrics.ObserveRemove(time.Since(start))
+	return nil
+}
+
+// TrySearch is like Search but returns the error for invalid queries and
+// empty indexes
+func (h *Hnsw) TrySearch(q framework.Point, ef uint64, K uint64) (*distqueue.DistQueue, error) {
+	return h.SearchContext(context.Background(), q, ef, K)
+}
@@ -0,0 +1,37 @@
+package hnsw
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	h := New(4, 100, []float32{0, 0})
+	a, err := h.TryAdd([]float32{1, 0})
+	assert.NoError(t, err)
+
+	_, err = h.TryAdd([]float32{1, 0, 0})
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	_, err = h.TryAdd([]float32{float32(math.NaN()), 0})
+	assert.Equal(t, ErrNaN, err)
+	_, err = h.TrySearch([]float32{float32(math.Inf(1)), 0}, 10, 1)
+	assert.Equal(t, ErrNaN, err)
+	_, err = h.TrySearch([]float32{1}, 10, 1)
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+
+	assert.Equal(t, ErrNotFound, h.TryRemove(42))
+	assert.Equal(t, ErrNotFound, h.SetTags(42, []uint64{1}))
+	assert.NotPanics(t, func() { h.Remove(42) })
+
+	assert.NoError(t, h.TryRemove(a))
+	res, err := h.TrySearch([]float32{1, 0}, 10, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), res.Pop().Node)
+
+	assert.NoError(t, h.TryRemove(0))
+	_, err = h.TrySearch([]float32{1, 0}, 10, 1)
+	assert.Equal(t, ErrEmptyIndex, err)
+}
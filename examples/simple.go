@@ -6,6 +6,8 @@ import (
 	"time"
 
 	hnsw "github.com/jnmly/go-hnsw"
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/f32"
 )
 
 func main() {
@@ -17,28 +19,36 @@ func main() {
 		K              = 10
 	)
 
-	var zero hnsw.Point = make([]float32, 128)
-
-	h := hnsw.New(M, efConstruction, zero)
-	h.Grow(10000)
+	h, err := hnsw.NewEmpty(M, efConstruction, 128, hnsw.L2)
+	if err != nil {
+		panic(err)
+	}
 
-	for i := 1; i <= 10000; i++ {
-		h.Add(randomPoint(), uint32(i))
-		if (i)%1000 == 0 {
-			fmt.Printf("%v points added\n", i)
+	points := make([][]float32, 10000)
+	for i := range points {
+		points[i] = randomPoint()
+		// the first point gets node id 0, the following ones count up
+		h.Add(points[i])
+		if (i+1)%1000 == 0 {
+			fmt.Printf("%v points added\n", i+1)
 		}
 	}
 
 	fmt.Printf("Generating queries and calculating true answers using bruteforce search...\n")
-	queries := make([]hnsw.Point, 1000)
-	truth := make([][]uint32, 1000)
+	queries := make([][]float32, 1000)
+	truth := make([]map[uint64]bool, 1000)
 	for i := range queries {
 		queries[i] = randomPoint()
-		result := h.SearchBrute(queries[i], K)
-		truth[i] = make([]uint32, K)
-		for j := K - 1; j >= 0; j-- {
-			item := result.Pop()
-			truth[i][j] = item.ID
+		result := &distqueue.DistQueue{ClosestLast: true}
+		for id, p := range points {
+			result.Push(uint64(id), f32.L2Squared(queries[i], p))
+			if result.Len() > K {
+				result.Pop()
+			}
+		}
+		truth[i] = make(map[uint64]bool, K)
+		for result.Len() > 0 {
+			truth[i][result.Pop().Node] = true
 		}
 	}
 
@@ -47,12 +57,9 @@ func main() {
 	start := time.Now()
 	for i := 0; i < 1000; i++ {
 		result := h.Search(queries[i], efSearch, K)
-		for j := 0; j < K; j++ {
-			item := result.Pop()
-			for k := 0; k < K; k++ {
-				if item.ID == truth[i][k] {
-					hits++
-				}
+		for result.Len() > 0 {
+			if truth[i][result.Pop().Node] {
+				hits++
 			}
 		}
 	}
@@ -63,8 +70,8 @@ func main() {
 
 }
 
-func randomPoint() hnsw.Point {
-	var v hnsw.Point = make([]float32, 128)
+func randomPoint() []float32 {
+	v := make([]float32, 128)
 	for i := range v {
 		v[i] = rand.Float32()
 	}
@@ -0,0 +1,181 @@
+package hnsw
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ExportOptions selects the part of the graph written by the exporters
+type ExportOptions struct {
+	// Levels restricts the export to the given levels, nil exports all
+	Levels []uint64
+	// Around restricts the export to the nodes within Hops links of these
+	// nodes, nil exports all nodes. Hops defaults to 1.
+	Around []uint64
+	Hops   int
+	// Distances adds the distance between the nodes as edge weight
+	Distances bool
+}
+
+type exportNode struct {
+	ID    uint64 `json:"id"`
+	Level uint64 `json:"level"`
+}
+
+type exportEdge struct {
+	Source uint64   `json:"source"`
+	Target uint64   `json:"target"`
+	Level  uint64   `json:"level"`
+	Weight *float32 `json:"weight,omitempty"`
+}
+
+type exportGraph struct {
+	Nodes []exportNode `json:"nodes"`
+	Edges []exportEdge `json:"edges"`
+}
+
+// exportGraph collects the nodes and friend links selected by opts
+func (h *Hnsw) exportGraph(opts ExportOptions) exportGraph {
+	h.RLock()
+	defer h.RUnlock()
+
+	var levels map[uint64]bool
+	if opts.Levels != nil {
+		levels = make(map[uint64]bool, len(opts.Levels))
+		for _, l := range opts.Levels {
+			levels[l] = true
+		}
+	}
+	onLevel := func(level uint64) bool {
+		return levels == nil || levels[level]
+	}
+
+	var friends []uint64
+	var included map[uint64]bool
+	if opts.Around != nil {
+		hops := opts.Hops
+		if hops == 0 {
+			hops = 1
+		}
+		included = make(map[uint64]bool)
+		queue := make([]uint64, 0, len(opts.Around))
+		for _, id := range opts.Around {
+			if h.node(id) != nil && !included[id] {
+				included[id] = true
+				queue = append(queue, id)
+			}
+		}
+		for ; hops > 0; hops-- {
+			var next []uint64
+			for _, id := range queue {
+				for level := uint64(0); level <= h.node(id).Level; level++ {
+					if !onLevel(level) {
+						continue
+					}
+					friends = h.friendsAt(id, level, friends[:0])
+					for _, f := range friends {
+						if !included[f] && h.node(f) != nil {
+							included[f] = true
+							next = append(next, f)
+						}
+					}
+				}
+			}
+			queue = next
+		}
+	}
+
+	var g exportGraph
+	nodes := h.nodeList()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	exported := make(map[uint64]bool, len(nodes))
+	for _, n := range nodes {
+		if included != nil && !included[n.Id] {
+			continue
+		}
+		for level := uint64(0); level <= n.Level; level++ {
+			if onLevel(level) {
+				exported[n.Id] = true
+				g.Nodes = append(g.Nodes, exportNode{ID: n.Id, Level: n.Level})
+				break
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		p := h.node(n.ID).P
+		for level := uint64(0); level <= n.Level; level++ {
+			if !onLevel(level) {
+				continue
+			}
+			friends = h.friendsAt(n.ID, level, friends[:0])
+			for _, f := range friends {
+				if !exported[f] {
+					continue
+				}
+				e := exportEdge{Source: n.ID, Target: f, Level: level}
+				if opts.Distances {
+					d := h.DistFunc(p, h.node(f).P)
+					e.Weight = &d
+				}
+				g.Edges = append(g.Edges, e)
+			}
+		}
+	}
+	return g
+}
+
+// ExportDOT writes the graph in Graphviz DOT format. Nodes and edges carry
+// their level in the custom hnsw_level attribute, with opts.Distances edges
+// are labelled with the distance.
+func (h *Hnsw) ExportDOT(w io.Writer, opts ExportOptions) error {
+	g := h.exportGraph(opts)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph hnsw {")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "  n%d [label=\"%d\" hnsw_level=%d];\n", n.ID, n.ID, n.Level)
+	}
+	for _, e := range g.Edges {
+		if e.Weight != nil {
+			fmt.Fprintf(bw, "  n%d -> n%d [hnsw_level=%d label=\"%g\"];\n", e.Source, e.Target, e.Level, *e.Weight)
+		} else {
+			fmt.Fprintf(bw, "  n%d -> n%d [hnsw_level=%d];\n", e.Source, e.Target, e.Level)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// ExportGraphML writes the graph in GraphML format
+func (h *Hnsw) ExportGraphML(w io.Writer, opts ExportOptions) error {
+	g := h.exportGraph(opts)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="level" for="all" attr.name="level" attr.type="long"/>`)
+	fmt.Fprintln(bw, `  <key id="weight" for="edge" attr.name="weight" attr.type="float"/>`)
+	fmt.Fprintln(bw, `  <graph id="hnsw" edgedefault="directed">`)
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "    <node id=\"n%d\"><data key=\"level\">%d</data></node>\n", n.ID, n.Level)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "    <edge source=\"n%d\" target=\"n%d\"><data key=\"level\">%d</data>", e.Source, e.Target, e.Level)
+		if e.Weight != nil {
+			fmt.Fprintf(bw, "<data key=\"weight\">%g</data>", *e.Weight)
+		}
+		fmt.Fprintln(bw, "</edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+// ExportJSON writes the graph as a JSON object with a list of nodes and a
+// list of edges
+func (h *Hnsw) ExportJSON(w io.Writer, opts ExportOptions) error {
+	return json.NewEncoder(w).Encode(h.exportGraph(opts))
+}
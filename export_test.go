@@ -0,0 +1,82 @@
+package hnsw
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:100] {
+		h.Add(v)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.ExportJSON(buf, ExportOptions{Distances: true}))
+	var g exportGraph
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &g))
+	assert.Equal(t, 100, len(g.Nodes))
+	edges := 0
+	for _, n := range h.Nodes {
+		for _, l := range n.Friends {
+			edges += len(l.Nodes)
+		}
+	}
+	assert.Equal(t, edges, len(g.Edges))
+	assert.NotNil(t, g.Edges[0].Weight)
+
+	buf.Reset()
+	assert.NoError(t, h.ExportDOT(buf, ExportOptions{}))
+	dot := buf.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph hnsw {"))
+	assert.Equal(t, edges, strings.Count(dot, "->"))
+	assert.NotContains(t, dot, "weight=")
+
+	buf.Reset()
+	assert.NoError(t, h.ExportDOT(buf, ExportOptions{Distances: true}))
+	assert.Equal(t, edges, strings.Count(buf.String(), "label=\"")-100)
+	assert.NotContains(t, buf.String(), "weight=")
+
+	buf.Reset()
+	assert.NoError(t, h.ExportGraphML(buf, ExportOptions{Distances: true}))
+	var doc struct {
+		Nodes []struct{} `xml:"graph>node"`
+		Edges []struct{} `xml:"graph>edge"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 100, len(doc.Nodes))
+	assert.Equal(t, edges, len(doc.Edges))
+}
+
+func TestExportFilters(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:100] {
+		h.Add(v)
+	}
+
+	g := h.exportGraph(ExportOptions{Levels: []uint64{1}})
+	for _, n := range g.Nodes {
+		assert.True(t, n.Level >= 1)
+	}
+	for _, e := range g.Edges {
+		assert.Equal(t, uint64(1), e.Level)
+	}
+
+	g = h.exportGraph(ExportOptions{Around: []uint64{5}, Levels: []uint64{0}})
+	ids := map[uint64]bool{}
+	for _, n := range g.Nodes {
+		ids[n.ID] = true
+	}
+	assert.True(t, ids[5])
+	assert.Equal(t, len(h.Nodes[5].Friends[0].Nodes)+1, len(g.Nodes))
+	for _, f := range h.Nodes[5].Friends[0].Nodes {
+		assert.True(t, ids[f])
+	}
+}
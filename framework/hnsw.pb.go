@@ -57,6 +57,8 @@ type Node struct {
 	Friends        map[uint64]*LinkList `protobuf:"bytes,3,rep,name=Friends" json:"Friends,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
 	ReverseFriends map[uint64]*LinkMap  `protobuf:"bytes,4,rep,name=ReverseFriends" json:"ReverseFriends,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
 	Id             uint64               `protobuf:"varint,5,opt,name=Id,proto3" json:"Id,omitempty"`
+	Tags           []uint64             `protobuf:"varint,6,rep,packed,name=Tags" json:"Tags,omitempty"`
+	Document       uint64               `protobuf:"varint,7,opt,name=Document,proto3" json:"Document,omitempty"`
 }
 
 func (m *Node) Reset()                    { *m = Node{} }
@@ -99,6 +101,20 @@ func (m *Node) GetId() uint64 {
 	return 0
 }
 
+func (m *Node) GetTags() []uint64 {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Node) GetDocument() uint64 {
+	if m != nil {
+		return m.Document
+	}
+	return 0
+}
+
 type Hnsw struct {
 	M              uint64            `protobuf:"varint,1,opt,name=M,proto3" json:"M,omitempty"`
 	M0             uint64            `protobuf:"varint,2,opt,name=M0,proto3" json:"M0,omitempty"`
@@ -110,6 +126,9 @@ type Hnsw struct {
 	CountLevel     map[uint64]uint64 `protobuf:"bytes,8,rep,name=CountLevel" json:"CountLevel,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	Enterpoint     uint64            `protobuf:"varint,9,opt,name=Enterpoint,proto3" json:"Enterpoint,omitempty"`
 	Nodes          map[uint64]*Node  `protobuf:"bytes,10,rep,name=Nodes" json:"Nodes,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	Transform      *Transform        `protobuf:"bytes,11,opt,name=Transform" json:"Transform,omitempty"`
+	Normalize      bool              `protobuf:"varint,12,opt,name=Normalize,proto3" json:"Normalize,omitempty"`
+	Dimension      uint64            `protobuf:"varint,13,opt,name=Dimension,proto3" json:"Dimension,omitempty"`
 }
 
 func (m *Hnsw) Reset()                    { *m = Hnsw{} }
@@ -187,11 +206,147 @@ func (m *Hnsw) GetNodes() map[uint64]*Node {
 	return nil
 }
 
+func (m *Hnsw) GetTransform() *Transform {
+	if m != nil {
+		return m.Transform
+	}
+	return nil
+}
+
+func (m *Hnsw) GetNormalize() bool {
+	if m != nil {
+		return m.Normalize
+	}
+	return false
+}
+
+func (m *Hnsw) GetDimension() uint64 {
+	if m != nil {
+		return m.Dimension
+	}
+	return 0
+}
+
+type Posting struct {
+	Id       uint64    `protobuf:"varint,1,opt,name=Id,proto3" json:"Id,omitempty"`
+	Centroid uint64    `protobuf:"varint,2,opt,name=Centroid,proto3" json:"Centroid,omitempty"`
+	P        []float32 `protobuf:"fixed32,3,rep,packed,name=P" json:"P,omitempty"`
+}
+
+func (m *Posting) Reset()                    { *m = Posting{} }
+func (m *Posting) String() string            { return proto.CompactTextString(m) }
+func (*Posting) ProtoMessage()               {}
+func (*Posting) Descriptor() ([]byte, []int) { return fileDescriptorHnsw, []int{4} }
+
+func (m *Posting) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Posting) GetCentroid() uint64 {
+	if m != nil {
+		return m.Centroid
+	}
+	return 0
+}
+
+func (m *Posting) GetP() []float32 {
+	if m != nil {
+		return m.P
+	}
+	return nil
+}
+
+type Ivf struct {
+	Centroids *Hnsw      `protobuf:"bytes,1,opt,name=Centroids" json:"Centroids,omitempty"`
+	Postings  []*Posting `protobuf:"bytes,2,rep,name=Postings" json:"Postings,omitempty"`
+	Sequence  uint64     `protobuf:"varint,3,opt,name=Sequence,proto3" json:"Sequence,omitempty"`
+}
+
+func (m *Ivf) Reset()                    { *m = Ivf{} }
+func (m *Ivf) String() string            { return proto.CompactTextString(m) }
+func (*Ivf) ProtoMessage()               {}
+func (*Ivf) Descriptor() ([]byte, []int) { return fileDescriptorHnsw, []int{5} }
+
+func (m *Ivf) GetCentroids() *Hnsw {
+	if m != nil {
+		return m.Centroids
+	}
+	return nil
+}
+
+func (m *Ivf) GetPostings() []*Posting {
+	if m != nil {
+		return m.Postings
+	}
+	return nil
+}
+
+func (m *Ivf) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+type Transform struct {
+	Kind      uint64    `protobuf:"varint,1,opt,name=Kind,proto3" json:"Kind,omitempty"`
+	InputDim  uint64    `protobuf:"varint,2,opt,name=InputDim,proto3" json:"InputDim,omitempty"`
+	OutputDim uint64    `protobuf:"varint,3,opt,name=OutputDim,proto3" json:"OutputDim,omitempty"`
+	Mean      []float32 `protobuf:"fixed32,4,rep,packed,name=Mean" json:"Mean,omitempty"`
+	Matrix    []float32 `protobuf:"fixed32,5,rep,packed,name=Matrix" json:"Matrix,omitempty"`
+}
+
+func (m *Transform) Reset()                    { *m = Transform{} }
+func (m *Transform) String() string            { return proto.CompactTextString(m) }
+func (*Transform) ProtoMessage()               {}
+func (*Transform) Descriptor() ([]byte, []int) { return fileDescriptorHnsw, []int{6} }
+
+func (m *Transform) GetKind() uint64 {
+	if m != nil {
+		return m.Kind
+	}
+	return 0
+}
+
+func (m *Transform) GetInputDim() uint64 {
+	if m != nil {
+		return m.InputDim
+	}
+	return 0
+}
+
+func (m *Transform) GetOutputDim() uint64 {
+	if m != nil {
+		return m.OutputDim
+	}
+	return 0
+}
+
+func (m *Transform) GetMean() []float32 {
+	if m != nil {
+		return m.Mean
+	}
+	return nil
+}
+
+func (m *Transform) GetMatrix() []float32 {
+	if m != nil {
+		return m.Matrix
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*LinkMap)(nil), "framework.LinkMap")
 	proto.RegisterType((*LinkList)(nil), "framework.LinkList")
 	proto.RegisterType((*Node)(nil), "framework.Node")
 	proto.RegisterType((*Hnsw)(nil), "framework.Hnsw")
+	proto.RegisterType((*Posting)(nil), "framework.Posting")
+	proto.RegisterType((*Ivf)(nil), "framework.Ivf")
+	proto.RegisterType((*Transform)(nil), "framework.Transform")
 }
 func (this *LinkMap) Equal(that interface{}) bool {
 	if that == nil {
@@ -318,6 +473,17 @@ func (this *Node) Equal(that interface{}) bool {
 	if this.Id != that1.Id {
 		return false
 	}
+	if len(this.Tags) != len(that1.Tags) {
+		return false
+	}
+	for i := range this.Tags {
+		if this.Tags[i] != that1.Tags[i] {
+			return false
+		}
+	}
+	if this.Document != that1.Document {
+		return false
+	}
 	return true
 }
 func (this *Hnsw) Equal(that interface{}) bool {
@@ -385,6 +551,149 @@ func (this *Hnsw) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if !this.Transform.Equal(that1.Transform) {
+		return false
+	}
+	if this.Normalize != that1.Normalize {
+		return false
+	}
+	if this.Dimension != that1.Dimension {
+		return false
+	}
+	return true
+}
+func (this *Posting) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Posting)
+	if !ok {
+		that2, ok := that.(Posting)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Id != that1.Id {
+		return false
+	}
+	if this.Centroid != that1.Centroid {
+		return false
+	}
+	if len(this.P) != len(that1.P) {
+		return false
+	}
+	for i := range this.P {
+		if this.P[i] != that1.P[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *Ivf) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Ivf)
+	if !ok {
+		that2, ok := that.(Ivf)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Centroids.Equal(that1.Centroids) {
+		return false
+	}
+	if len(this.Postings) != len(that1.Postings) {
+		return false
+	}
+	for i := range this.Postings {
+		if !this.Postings[i].Equal(that1.Postings[i]) {
+			return false
+		}
+	}
+	if this.Sequence != that1.Sequence {
+		return false
+	}
+	return true
+}
+func (this *Transform) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Transform)
+	if !ok {
+		that2, ok := that.(Transform)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Kind != that1.Kind {
+		return false
+	}
+	if this.InputDim != that1.InputDim {
+		return false
+	}
+	if this.OutputDim != that1.OutputDim {
+		return false
+	}
+	if len(this.Mean) != len(that1.Mean) {
+		return false
+	}
+	for i := range this.Mean {
+		if this.Mean[i] != that1.Mean[i] {
+			return false
+		}
+	}
+	if len(this.Matrix) != len(that1.Matrix) {
+		return false
+	}
+	for i := range this.Matrix {
+		if this.Matrix[i] != that1.Matrix[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *LinkMap) GoString() string {
@@ -423,7 +732,7 @@ func (this *Node) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 11)
 	s = append(s, "&framework.Node{")
 	s = append(s, "P: "+fmt.Sprintf("%#v", this.P)+",\n")
 	s = append(s, "Level: "+fmt.Sprintf("%#v", this.Level)+",\n")
@@ -454,6 +763,8 @@ func (this *Node) GoString() string {
 		s = append(s, "ReverseFriends: "+mapStringForReverseFriends+",\n")
 	}
 	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	s = append(s, "Tags: "+fmt.Sprintf("%#v", this.Tags)+",\n")
+	s = append(s, "Document: "+fmt.Sprintf("%#v", this.Document)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -461,7 +772,7 @@ func (this *Hnsw) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 14)
+	s := make([]string, 0, 17)
 	s = append(s, "&framework.Hnsw{")
 	s = append(s, "M: "+fmt.Sprintf("%#v", this.M)+",\n")
 	s = append(s, "M0: "+fmt.Sprintf("%#v", this.M0)+",\n")
@@ -497,6 +808,53 @@ func (this *Hnsw) GoString() string {
 	if this.Nodes != nil {
 		s = append(s, "Nodes: "+mapStringForNodes+",\n")
 	}
+	if this.Transform != nil {
+		s = append(s, "Transform: "+fmt.Sprintf("%#v", this.Transform)+",\n")
+	}
+	s = append(s, "Normalize: "+fmt.Sprintf("%#v", this.Normalize)+",\n")
+	s = append(s, "Dimension: "+fmt.Sprintf("%#v", this.Dimension)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Posting) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&framework.Posting{")
+	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	s = append(s, "Centroid: "+fmt.Sprintf("%#v", this.Centroid)+",\n")
+	s = append(s, "P: "+fmt.Sprintf("%#v", this.P)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Ivf) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&framework.Ivf{")
+	if this.Centroids != nil {
+		s = append(s, "Centroids: "+fmt.Sprintf("%#v", this.Centroids)+",\n")
+	}
+	if this.Postings != nil {
+		s = append(s, "Postings: "+fmt.Sprintf("%#v", this.Postings)+",\n")
+	}
+	s = append(s, "Sequence: "+fmt.Sprintf("%#v", this.Sequence)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Transform) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&framework.Transform{")
+	s = append(s, "Kind: "+fmt.Sprintf("%#v", this.Kind)+",\n")
+	s = append(s, "InputDim: "+fmt.Sprintf("%#v", this.InputDim)+",\n")
+	s = append(s, "OutputDim: "+fmt.Sprintf("%#v", this.OutputDim)+",\n")
+	s = append(s, "Mean: "+fmt.Sprintf("%#v", this.Mean)+",\n")
+	s = append(s, "Matrix: "+fmt.Sprintf("%#v", this.Matrix)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -676,6 +1034,28 @@ func (m *Node) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintHnsw(dAtA, i, uint64(m.Id))
 	}
+	if len(m.Tags) > 0 {
+		dAtA9 := make([]byte, len(m.Tags)*10)
+		var j10 int
+		for _, num := range m.Tags {
+			for num >= 1<<7 {
+				dAtA9[j10] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j10++
+			}
+			dAtA9[j10] = uint8(num)
+			j10++
+		}
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(j10))
+		i += copy(dAtA[i:], dAtA9[:j10])
+	}
+	if m.Document != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Document))
+	}
 	return i, nil
 }
 
@@ -776,12 +1156,191 @@ func (m *Hnsw) MarshalTo(dAtA []byte) (int, error) {
 			}
 		}
 	}
-	return i, nil
-}
-
-func encodeFixed64Hnsw(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
+	if m.Transform != nil {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Transform.Size()))
+		n11, err := m.Transform.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
+	}
+	if m.Normalize {
+		dAtA[i] = 0x60
+		i++
+		if m.Normalize {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Dimension != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Dimension))
+	}
+	return i, nil
+}
+
+func (m *Posting) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Posting) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Id != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Id))
+	}
+	if m.Centroid != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Centroid))
+	}
+	if len(m.P) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(len(m.P)*4))
+		for _, num := range m.P {
+			f7 := math.Float32bits(float32(num))
+			dAtA[i] = uint8(f7)
+			i++
+			dAtA[i] = uint8(f7 >> 8)
+			i++
+			dAtA[i] = uint8(f7 >> 16)
+			i++
+			dAtA[i] = uint8(f7 >> 24)
+			i++
+		}
+	}
+	return i, nil
+}
+
+func (m *Ivf) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Ivf) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Centroids != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Centroids.Size()))
+		n8, err := m.Centroids.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
+	if len(m.Postings) > 0 {
+		for _, msg := range m.Postings {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintHnsw(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Sequence != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Sequence))
+	}
+	return i, nil
+}
+
+func (m *Transform) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Transform) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Kind != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.Kind))
+	}
+	if m.InputDim != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.InputDim))
+	}
+	if m.OutputDim != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(m.OutputDim))
+	}
+	if len(m.Mean) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(len(m.Mean)*4))
+		for _, num := range m.Mean {
+			f12 := math.Float32bits(float32(num))
+			dAtA[i] = uint8(f12)
+			i++
+			dAtA[i] = uint8(f12 >> 8)
+			i++
+			dAtA[i] = uint8(f12 >> 16)
+			i++
+			dAtA[i] = uint8(f12 >> 24)
+			i++
+		}
+	}
+	if len(m.Matrix) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintHnsw(dAtA, i, uint64(len(m.Matrix)*4))
+		for _, num := range m.Matrix {
+			f13 := math.Float32bits(float32(num))
+			dAtA[i] = uint8(f13)
+			i++
+			dAtA[i] = uint8(f13 >> 8)
+			i++
+			dAtA[i] = uint8(f13 >> 16)
+			i++
+			dAtA[i] = uint8(f13 >> 24)
+			i++
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64Hnsw(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
 	dAtA[offset+2] = uint8(v >> 16)
 	dAtA[offset+3] = uint8(v >> 24)
 	dAtA[offset+4] = uint8(v >> 32)
@@ -871,6 +1430,16 @@ func (m *Node) Size() (n int) {
 	if m.Id != 0 {
 		n += 1 + sovHnsw(uint64(m.Id))
 	}
+	if len(m.Tags) > 0 {
+		l = 0
+		for _, e := range m.Tags {
+			l += sovHnsw(uint64(e))
+		}
+		n += 1 + sovHnsw(uint64(l)) + l
+	}
+	if m.Document != 0 {
+		n += 1 + sovHnsw(uint64(m.Document))
+	}
 	return n
 }
 
@@ -922,6 +1491,71 @@ func (m *Hnsw) Size() (n int) {
 			n += mapEntrySize + 1 + sovHnsw(uint64(mapEntrySize))
 		}
 	}
+	if m.Transform != nil {
+		l = m.Transform.Size()
+		n += 1 + l + sovHnsw(uint64(l))
+	}
+	if m.Normalize {
+		n += 2
+	}
+	if m.Dimension != 0 {
+		n += 1 + sovHnsw(uint64(m.Dimension))
+	}
+	return n
+}
+
+func (m *Posting) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 1 + sovHnsw(uint64(m.Id))
+	}
+	if m.Centroid != 0 {
+		n += 1 + sovHnsw(uint64(m.Centroid))
+	}
+	if len(m.P) > 0 {
+		n += 1 + sovHnsw(uint64(len(m.P)*4)) + len(m.P)*4
+	}
+	return n
+}
+
+func (m *Ivf) Size() (n int) {
+	var l int
+	_ = l
+	if m.Centroids != nil {
+		l = m.Centroids.Size()
+		n += 1 + l + sovHnsw(uint64(l))
+	}
+	if len(m.Postings) > 0 {
+		for _, e := range m.Postings {
+			l = e.Size()
+			n += 1 + l + sovHnsw(uint64(l))
+		}
+	}
+	if m.Sequence != 0 {
+		n += 1 + sovHnsw(uint64(m.Sequence))
+	}
+	return n
+}
+
+func (m *Transform) Size() (n int) {
+	var l int
+	_ = l
+	if m.Kind != 0 {
+		n += 1 + sovHnsw(uint64(m.Kind))
+	}
+	if m.InputDim != 0 {
+		n += 1 + sovHnsw(uint64(m.InputDim))
+	}
+	if m.OutputDim != 0 {
+		n += 1 + sovHnsw(uint64(m.OutputDim))
+	}
+	if len(m.Mean) > 0 {
+		n += 1 + sovHnsw(uint64(len(m.Mean)*4)) + len(m.Mean)*4
+	}
+	if len(m.Matrix) > 0 {
+		n += 1 + sovHnsw(uint64(len(m.Matrix)*4)) + len(m.Matrix)*4
+	}
 	return n
 }
 
@@ -1541,6 +2175,87 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHnsw
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Tags = append(m.Tags, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHnsw
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthHnsw
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowHnsw
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Tags = append(m.Tags, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Document", wireType)
+			}
+			m.Document = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Document |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHnsw(dAtA[iNdEx:])
@@ -1950,6 +2665,562 @@ func (m *Hnsw) Unmarshal(dAtA []byte) error {
 			}
 			m.Nodes[mapkey] = mapvalue
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Transform", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Transform == nil {
+				m.Transform = &Transform{}
+			}
+			if err := m.Transform.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Normalize", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Normalize = bool(v != 0)
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dimension", wireType)
+			}
+			m.Dimension = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dimension |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipHnsw(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Posting) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowHnsw
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Posting: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Posting: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			m.Id = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Id |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Centroid", wireType)
+			}
+			m.Centroid = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Centroid |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType == 5 {
+				var v uint32
+				if (iNdEx + 4) > l {
+					return io.ErrUnexpectedEOF
+				}
+				iNdEx += 4
+				v = uint32(dAtA[iNdEx-4])
+				v |= uint32(dAtA[iNdEx-3]) << 8
+				v |= uint32(dAtA[iNdEx-2]) << 16
+				v |= uint32(dAtA[iNdEx-1]) << 24
+				v2 := float32(math.Float32frombits(v))
+				m.P = append(m.P, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHnsw
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthHnsw
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					if (iNdEx + 4) > l {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += 4
+					v = uint32(dAtA[iNdEx-4])
+					v |= uint32(dAtA[iNdEx-3]) << 8
+					v |= uint32(dAtA[iNdEx-2]) << 16
+					v |= uint32(dAtA[iNdEx-1]) << 24
+					v2 := float32(math.Float32frombits(v))
+					m.P = append(m.P, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field P", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipHnsw(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Ivf) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowHnsw
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Ivf: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Ivf: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Centroids", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Centroids == nil {
+				m.Centroids = &Hnsw{}
+			}
+			if err := m.Centroids.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Postings", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Postings = append(m.Postings, &Posting{})
+			if err := m.Postings[len(m.Postings)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sequence", wireType)
+			}
+			m.Sequence = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Sequence |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipHnsw(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthHnsw
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Transform) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowHnsw
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Transform: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Transform: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Kind", wireType)
+			}
+			m.Kind = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Kind |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InputDim", wireType)
+			}
+			m.InputDim = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InputDim |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutputDim", wireType)
+			}
+			m.OutputDim = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHnsw
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OutputDim |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 5 {
+				var v uint32
+				if (iNdEx + 4) > l {
+					return io.ErrUnexpectedEOF
+				}
+				iNdEx += 4
+				v = uint32(dAtA[iNdEx-4])
+				v |= uint32(dAtA[iNdEx-3]) << 8
+				v |= uint32(dAtA[iNdEx-2]) << 16
+				v |= uint32(dAtA[iNdEx-1]) << 24
+				v2 := float32(math.Float32frombits(v))
+				m.Mean = append(m.Mean, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHnsw
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthHnsw
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					if (iNdEx + 4) > l {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += 4
+					v = uint32(dAtA[iNdEx-4])
+					v |= uint32(dAtA[iNdEx-3]) << 8
+					v |= uint32(dAtA[iNdEx-2]) << 16
+					v |= uint32(dAtA[iNdEx-1]) << 24
+					v2 := float32(math.Float32frombits(v))
+					m.Mean = append(m.Mean, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mean", wireType)
+			}
+		case 5:
+			if wireType == 5 {
+				var v uint32
+				if (iNdEx + 4) > l {
+					return io.ErrUnexpectedEOF
+				}
+				iNdEx += 4
+				v = uint32(dAtA[iNdEx-4])
+				v |= uint32(dAtA[iNdEx-3]) << 8
+				v |= uint32(dAtA[iNdEx-2]) << 16
+				v |= uint32(dAtA[iNdEx-1]) << 24
+				v2 := float32(math.Float32frombits(v))
+				m.Matrix = append(m.Matrix, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHnsw
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthHnsw
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					if (iNdEx + 4) > l {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += 4
+					v = uint32(dAtA[iNdEx-4])
+					v |= uint32(dAtA[iNdEx-3]) << 8
+					v |= uint32(dAtA[iNdEx-2]) << 16
+					v |= uint32(dAtA[iNdEx-1]) << 24
+					v2 := float32(math.Float32frombits(v))
+					m.Matrix = append(m.Matrix, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matrix", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHnsw(dAtA[iNdEx:])
@@ -2079,39 +3350,51 @@ var (
 func init() { proto.RegisterFile("hnsw.proto", fileDescriptorHnsw) }
 
 var fileDescriptorHnsw = []byte{
-	// 533 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xd1, 0x6e, 0xd3, 0x30,
-	0x14, 0xc5, 0x49, 0xba, 0xb6, 0x77, 0x55, 0x87, 0x3c, 0x1e, 0xac, 0x68, 0x84, 0xaa, 0x08, 0x54,
-	0x1e, 0xc8, 0xaa, 0x4d, 0x42, 0x13, 0x12, 0x42, 0x62, 0x14, 0x51, 0xd1, 0xc0, 0x14, 0xe0, 0x03,
-	0xd2, 0xd6, 0xed, 0xa2, 0x76, 0x76, 0x49, 0x9c, 0x76, 0xf9, 0x0b, 0x3e, 0x83, 0x4f, 0xe1, 0x91,
-	0x4f, 0x80, 0xee, 0x81, 0x57, 0x3e, 0x01, 0xd9, 0x49, 0x57, 0x37, 0xab, 0xd4, 0x37, 0xdf, 0x7b,
-	0xcf, 0x3d, 0xb9, 0x3e, 0xc7, 0x37, 0x00, 0x97, 0x2c, 0x5e, 0xb8, 0xb3, 0x88, 0x0b, 0x8e, 0xab,
-	0xa3, 0x28, 0xb8, 0xa2, 0x0b, 0x1e, 0x4d, 0xec, 0xe7, 0xe3, 0x50, 0x5c, 0x26, 0x7d, 0x77, 0xc0,
-	0xaf, 0x8e, 0xc7, 0x7c, 0xcc, 0x8f, 0x15, 0xa2, 0x9f, 0x8c, 0x54, 0xa4, 0x02, 0x75, 0xca, 0x3a,
-	0x9b, 0xd7, 0x50, 0xee, 0x85, 0x6c, 0xe2, 0x05, 0x33, 0x7c, 0x0a, 0xa5, 0x8f, 0x7c, 0x48, 0x63,
-	0x82, 0x1a, 0x66, 0x6b, 0xff, 0xe4, 0xa1, 0x7b, 0x4b, 0xea, 0xe6, 0x10, 0x57, 0xd5, 0x3b, 0x4c,
-	0x44, 0xa9, 0x9f, 0x61, 0xed, 0x33, 0x80, 0x75, 0x12, 0xdf, 0x07, 0x73, 0x42, 0x53, 0x82, 0x1a,
-	0xa8, 0x65, 0xf9, 0xf2, 0x88, 0x1f, 0x40, 0x69, 0x1e, 0x4c, 0x13, 0x4a, 0x8c, 0x06, 0x6a, 0x55,
-	0xfc, 0x2c, 0x78, 0x69, 0x9c, 0xa1, 0x66, 0x03, 0x2a, 0x92, 0xb6, 0x17, 0xc6, 0x42, 0xa2, 0xd6,
-	0x9f, 0xb6, 0x72, 0xee, 0xe6, 0x5f, 0x03, 0x2c, 0x79, 0xc2, 0x35, 0x40, 0x17, 0xaa, 0x64, 0xf8,
-	0xe8, 0x42, 0x82, 0x7b, 0x74, 0x4e, 0xa7, 0x8a, 0xd2, 0xf2, 0xb3, 0x00, 0xbf, 0x80, 0xf2, 0xbb,
-	0x28, 0xa4, 0x6c, 0x18, 0x13, 0x53, 0xcd, 0x7f, 0xa4, 0xcd, 0x2f, 0x59, 0xdc, 0xbc, 0x9c, 0x8d,
-	0xbf, 0x02, 0xe3, 0x0f, 0x50, 0xf7, 0xe9, 0x9c, 0x46, 0x31, 0x5d, 0xb5, 0x5b, 0xaa, 0xfd, 0x71,
-	0xb1, 0x7d, 0x13, 0x95, 0xb1, 0x14, 0x5a, 0x71, 0x1d, 0x8c, 0xee, 0x90, 0x94, 0xd4, 0x5c, 0x46,
-	0x77, 0x68, 0x7f, 0x82, 0x9a, 0x8e, 0xdf, 0xa2, 0xcf, 0x33, 0x5d, 0x9f, 0xfd, 0x93, 0xc3, 0x82,
-	0xe8, 0x52, 0x1d, 0x4d, 0x34, 0xfb, 0x2b, 0x1c, 0x6e, 0x99, 0x63, 0x0b, 0x6f, 0x6b, 0x93, 0x17,
-	0xdf, 0x35, 0x53, 0xf7, 0xe2, 0xc6, 0x04, 0xeb, 0x3d, 0x8b, 0x17, 0x52, 0x69, 0x2f, 0xa7, 0x41,
-	0x9e, 0xbc, 0x8e, 0xd7, 0xce, 0x65, 0x36, 0xbc, 0x36, 0x7e, 0x0a, 0xf5, 0xce, 0xe8, 0x9c, 0xb3,
-	0x58, 0x44, 0xc9, 0x40, 0x84, 0x9c, 0x11, 0x53, 0xd5, 0x0a, 0x59, 0xdc, 0x84, 0xda, 0x5b, 0x3a,
-	0x0d, 0x12, 0x16, 0xa4, 0x5f, 0xd2, 0x19, 0x25, 0x96, 0x42, 0x6d, 0xe4, 0xf0, 0x11, 0x54, 0x95,
-	0x71, 0x5e, 0x32, 0x15, 0x4a, 0x31, 0xe4, 0xaf, 0x13, 0xd8, 0x86, 0x8a, 0x17, 0x5c, 0xf7, 0x82,
-	0x94, 0x46, 0x64, 0x4f, 0x75, 0xdf, 0xc6, 0xb2, 0xf6, 0x99, 0x7e, 0x4b, 0x28, 0x1b, 0x50, 0x52,
-	0xce, 0x6a, 0xab, 0x18, 0xbf, 0x06, 0x38, 0xe7, 0x09, 0x13, 0xd9, 0x03, 0xa9, 0x28, 0x27, 0x1f,
-	0x69, 0x77, 0x97, 0x97, 0x74, 0xd7, 0x88, 0xcc, 0x45, 0xad, 0x05, 0x3b, 0x00, 0x1d, 0x26, 0x68,
-	0x34, 0xe3, 0x21, 0x13, 0xa4, 0xaa, 0xe8, 0xb5, 0x0c, 0x6e, 0xaf, 0x5e, 0x2a, 0x28, 0x6e, 0xbb,
-	0xc8, 0x7d, 0x77, 0x43, 0x5e, 0xc1, 0x41, 0xe1, 0x83, 0xbb, 0xd6, 0xc4, 0xd2, 0x1d, 0xef, 0xee,
-	0x58, 0xb0, 0x27, 0x9b, 0x46, 0x1f, 0x14, 0x9e, 0xad, 0x46, 0xf5, 0x86, 0xfc, 0xfb, 0xe3, 0xa0,
-	0x1f, 0x4b, 0x07, 0xfd, 0x5c, 0x3a, 0xe8, 0xd7, 0xd2, 0x41, 0xbf, 0x97, 0x0e, 0xfa, 0x7e, 0xe3,
-	0xdc, 0xeb, 0xef, 0xa9, 0x9f, 0xc1, 0xe9, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0x06, 0x45, 0x19,
-	0xde, 0x54, 0x04, 0x00, 0x00,
+	// 735 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xcd, 0x6e, 0xd3, 0x4a,
+	0x14, 0xbe, 0x63, 0x3b, 0x7f, 0xa7, 0xb9, 0xed, 0xd5, 0xb4, 0xba, 0x1a, 0x59, 0x25, 0x44, 0x41,
+	0xa0, 0xb0, 0xa8, 0x5b, 0xb5, 0x12, 0xaa, 0x90, 0x10, 0x12, 0x49, 0x11, 0x51, 0xe3, 0xb6, 0x32,
+	0xe5, 0x01, 0xdc, 0x64, 0x92, 0x5a, 0x8d, 0x67, 0x82, 0x3d, 0x4e, 0x1b, 0x56, 0x6c, 0xd8, 0xb3,
+	0xe3, 0x15, 0x78, 0x14, 0x96, 0x48, 0xbc, 0x00, 0x84, 0x17, 0xe0, 0x11, 0xd0, 0x8c, 0xed, 0xd8,
+	0x71, 0x2b, 0x75, 0x37, 0xe7, 0x9c, 0xef, 0x7c, 0x73, 0xfe, 0x01, 0x2e, 0x59, 0x78, 0x6d, 0x4d,
+	0x03, 0x2e, 0x38, 0xae, 0x8d, 0x02, 0xd7, 0xa7, 0xd7, 0x3c, 0xb8, 0x32, 0x77, 0xc6, 0x9e, 0xb8,
+	0x8c, 0x2e, 0xac, 0x01, 0xf7, 0x77, 0xc7, 0x7c, 0xcc, 0x77, 0x15, 0xe2, 0x22, 0x1a, 0x29, 0x49,
+	0x09, 0xea, 0x15, 0x7b, 0xb6, 0x6e, 0xa0, 0xd2, 0xf7, 0xd8, 0x95, 0xed, 0x4e, 0xf1, 0x01, 0x94,
+	0x4e, 0xf8, 0x90, 0x86, 0x04, 0x35, 0xf5, 0xf6, 0xda, 0xfe, 0x03, 0x6b, 0x49, 0x6a, 0x25, 0x10,
+	0x4b, 0xd9, 0x8f, 0x98, 0x08, 0xe6, 0x4e, 0x8c, 0x35, 0x0f, 0x01, 0x32, 0x25, 0xfe, 0x0f, 0xf4,
+	0x2b, 0x3a, 0x27, 0xa8, 0x89, 0xda, 0x86, 0x23, 0x9f, 0x78, 0x0b, 0x4a, 0x33, 0x77, 0x12, 0x51,
+	0xa2, 0x35, 0x51, 0xbb, 0xea, 0xc4, 0xc2, 0x73, 0xed, 0x10, 0xb5, 0x9a, 0x50, 0x95, 0xb4, 0x7d,
+	0x2f, 0x14, 0x12, 0x95, 0x7d, 0x6d, 0x24, 0xdc, 0xad, 0x2f, 0x3a, 0x18, 0xf2, 0x85, 0xeb, 0x80,
+	0xce, 0x94, 0x49, 0x73, 0xd0, 0x99, 0x04, 0xf7, 0xe9, 0x8c, 0x4e, 0x14, 0xa5, 0xe1, 0xc4, 0x02,
+	0x7e, 0x06, 0x95, 0xd7, 0x81, 0x47, 0xd9, 0x30, 0x24, 0xba, 0x8a, 0x7f, 0x3b, 0x17, 0xbf, 0x64,
+	0xb1, 0x12, 0x73, 0x1c, 0x7e, 0x0a, 0xc6, 0xc7, 0xb0, 0xee, 0xd0, 0x19, 0x0d, 0x42, 0x9a, 0xba,
+	0x1b, 0xca, 0xfd, 0x51, 0xd1, 0x7d, 0x15, 0x15, 0xb3, 0x14, 0x5c, 0xf1, 0x3a, 0x68, 0xbd, 0x21,
+	0x29, 0xa9, 0xb8, 0xb4, 0xde, 0x10, 0x63, 0x30, 0xce, 0xdd, 0x71, 0x48, 0xca, 0x2a, 0x2d, 0xf5,
+	0xc6, 0x26, 0x54, 0xbb, 0x7c, 0x10, 0xf9, 0x94, 0x09, 0x52, 0x51, 0xc8, 0xa5, 0x6c, 0x9e, 0x42,
+	0x3d, 0xcf, 0x7f, 0x47, 0x3d, 0x9f, 0xe6, 0xeb, 0xb9, 0xb6, 0xbf, 0x59, 0x68, 0x92, 0xac, 0x66,
+	0xae, 0xc8, 0xe6, 0x3b, 0xd8, 0xbc, 0x23, 0xee, 0x3b, 0x78, 0xdb, 0xab, 0xbc, 0xf8, 0x76, 0xf3,
+	0xf3, 0xbd, 0xfb, 0x61, 0x80, 0xf1, 0x86, 0x85, 0xd7, 0xb2, 0x33, 0x76, 0x42, 0x83, 0x6c, 0x99,
+	0xbe, 0xbd, 0x97, 0xb4, 0x45, 0xb3, 0xf7, 0xf0, 0x13, 0x58, 0x3f, 0x1a, 0x75, 0x38, 0x0b, 0x45,
+	0x10, 0x0d, 0x84, 0xc7, 0x19, 0xd1, 0x95, 0xad, 0xa0, 0xc5, 0x2d, 0xa8, 0x77, 0xe9, 0xc4, 0x8d,
+	0x98, 0x3b, 0x3f, 0x9f, 0x4f, 0x29, 0x31, 0x14, 0x6a, 0x45, 0x87, 0xb7, 0xa1, 0xa6, 0x1a, 0x6d,
+	0x47, 0x13, 0xa1, 0x2a, 0x8c, 0x9c, 0x4c, 0x21, 0x8b, 0x6a, 0xbb, 0x37, 0x7d, 0x77, 0x4e, 0x03,
+	0x52, 0x8e, 0x8b, 0x9a, 0xca, 0xd2, 0xf6, 0x96, 0xbe, 0x8f, 0x28, 0x1b, 0xd0, 0xb4, 0xe0, 0xa9,
+	0x8c, 0x5f, 0x02, 0x74, 0x78, 0xc4, 0x44, 0x3c, 0x50, 0x55, 0xd5, 0xf9, 0x87, 0xb9, 0xdc, 0x65,
+	0x92, 0x56, 0x86, 0x88, 0xbb, 0x9e, 0x73, 0xc1, 0x0d, 0x80, 0x23, 0x26, 0x68, 0x30, 0xe5, 0x1e,
+	0x13, 0xa4, 0xa6, 0xe8, 0x73, 0x1a, 0xbc, 0x97, 0x4e, 0x36, 0x28, 0x6e, 0xb3, 0xc8, 0x7d, 0x6b,
+	0xa3, 0xf0, 0x3e, 0xd4, 0xce, 0x03, 0x97, 0x85, 0x23, 0x1e, 0xf8, 0x64, 0x4d, 0x75, 0x63, 0x2b,
+	0xe7, 0xb5, 0xb4, 0x39, 0x19, 0x4c, 0x16, 0xe7, 0x84, 0x07, 0xbe, 0x3b, 0xf1, 0x3e, 0x50, 0x52,
+	0x57, 0x9b, 0x96, 0x29, 0xa4, 0xb5, 0xeb, 0xf9, 0x94, 0x85, 0xb2, 0x03, 0xff, 0xaa, 0x10, 0x33,
+	0x85, 0xf9, 0x02, 0x36, 0x0a, 0x09, 0xde, 0xb7, 0xc6, 0x46, 0x7e, 0xc2, 0x7a, 0xf7, 0x1c, 0x80,
+	0xc7, 0xab, 0x83, 0xb5, 0x51, 0x58, 0xab, 0xfc, 0x54, 0x75, 0xa0, 0x72, 0xc6, 0x43, 0xe1, 0xb1,
+	0x71, 0xb2, 0x48, 0x68, 0xb9, 0x48, 0x26, 0x54, 0x3b, 0x94, 0x89, 0x80, 0x7b, 0xc3, 0x24, 0x84,
+	0xa5, 0x1c, 0x5f, 0x07, 0x3d, 0xb9, 0x0e, 0xad, 0x8f, 0x08, 0xf4, 0xde, 0x6c, 0x84, 0x77, 0xa0,
+	0x96, 0x22, 0x42, 0x82, 0x6e, 0xfd, 0x2d, 0x8b, 0xef, 0x64, 0x08, 0x6c, 0x41, 0x35, 0xf9, 0x3b,
+	0x24, 0x5a, 0x53, 0x2f, 0xac, 0x40, 0x62, 0x72, 0x96, 0x98, 0x95, 0xa1, 0xd2, 0x57, 0x87, 0xaa,
+	0xf5, 0x09, 0xe5, 0x5a, 0x28, 0x6f, 0xc0, 0xb1, 0xc7, 0xd2, 0x64, 0xd4, 0x5b, 0x7a, 0xf7, 0xd8,
+	0x34, 0x12, 0x5d, 0xcf, 0x4f, 0xd3, 0x49, 0x65, 0xd9, 0xad, 0xd3, 0x48, 0x24, 0xc6, 0x98, 0x3a,
+	0x53, 0x48, 0x36, 0x9b, 0xba, 0x4c, 0x1d, 0x29, 0xcd, 0x51, 0x6f, 0xfc, 0x3f, 0x94, 0x6d, 0x57,
+	0x04, 0xde, 0x0d, 0x29, 0x29, 0x6d, 0x22, 0xbd, 0x22, 0x7f, 0x7e, 0x35, 0xd0, 0xd7, 0x45, 0x03,
+	0x7d, 0x5b, 0x34, 0xd0, 0xf7, 0x45, 0x03, 0xfd, 0x5c, 0x34, 0xd0, 0xe7, 0xdf, 0x8d, 0x7f, 0x2e,
+	0xca, 0xea, 0xf8, 0x1f, 0xfc, 0x1d, 0x00, 0x4d, 0x1e, 0xaf, 0xe1, 0x44, 0x06, 0x00, 0x00,
 }
@@ -1,9 +1,15 @@
 package hnsw
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jnmly/go-hnsw/bitsetpool"
 	"github.com/jnmly/go-hnsw/distqueue"
@@ -16,21 +22,45 @@ const (
 	deluanayTypeHeuristic
 )
 
+// number of candidates searchAtLayer evaluates between checks of its context
+const ctxCheckInterval = 16
+
 type Hnsw struct {
 	sync.RWMutex
 	framework.Hnsw
 
 	DistFunc func([]float32, []float32) float32
 
-	bitset *bitsetpool.BitsetPool
+	bitset    *bitsetpool.BitsetPool
+	wal       *WAL
+	metrics   Metrics
+	snapshots map[*Snapshot]bool
+	tags      tagIndex
+	documents documentIndex
+
+	// Add and Search hold the embedded read lock and run concurrently,
+	// Remove and Snapshot take the write lock. While inserts are running
+	// nodesLock guards the Nodes and CountLevel maps, the striped node
+	// locks guard the friend lists and epLock serialises updates of
+	// Enterpoint and MaxLayer. None of these is held while acquiring
+	// another one.
+	nodesLock sync.RWMutex
+	epLock    sync.Mutex
+	locks     [nodeLockStripes]sync.RWMutex
 }
 
-func (h *Hnsw) link(first *framework.Node, second uint64, level uint64) {
+func (h *Hnsw) link(firstID uint64, second uint64, level uint64) {
 	maxL := h.M
 	if level == 0 {
 		maxL = h.M0
 	}
 
+	first := h.node(firstID)
+	l := h.nodeLock(firstID)
+
+	l.Lock()
+	h.preserve(firstID, first)
+
 	// check if we have allocated friends slices up to this level?
 	if first.FriendLevelCount() < level+1 {
 		first.AllocateFriendsUpTo(level, maxL)
@@ -38,56 +68,90 @@ func (h *Hnsw) link(first *framework.Node, second uint64, level uint64) {
 
 	// link with second node
 	first.Friends[level].Nodes = append(first.Friends[level].Nodes, second) // HERE
-	h.Nodes[second].AddReverseLink(first.GetNodeId(), level)
-
+	var candidates []uint64
 	if first.FriendCountAtLevel(level) > maxL {
+		candidates = append(candidates, first.Friends[level].Nodes...)
+	}
+	l.Unlock()
+
+	h.addReverseLink(second, firstID, level)
+
+	// too many links, deal with it. The distances are calculated without
+	// holding the lock, if another insert changed the friends in the
+	// meantime start over with the new list.
+	for uint64(len(candidates)) > maxL {
+		selected := h.selectFriends(first.P, candidates, maxL)
+
+		l.Lock()
+		current := first.Friends[level].Nodes
+		if !equalIDs(current, candidates) {
+			candidates = append(candidates[:0], current...)
+			l.Unlock()
+			continue
+		}
+		h.preserve(firstID, first)
+		// FRIENDS ARE STORED IN DISTANCE ORDER, closest at index 0
+		first.Friends[level].Nodes = selected
+		l.Unlock()
+
+		// js: cleanup old reverse links
+		kept := make(map[uint64]bool, len(selected))
+		for _, n := range selected {
+			kept[n] = true
+		}
+		for _, oldFriend := range candidates {
+			if !kept[oldFriend] {
+				h.removeReverseLink(oldFriend, firstID, level)
+			}
+		}
+		return
+	}
+}
 
-		// too many links, deal with it
+// selectFriends returns the maxL nodes out of candidates to keep as friends
+// of a node at position p, closest first
+func (h *Hnsw) selectFriends(p framework.Point, candidates []uint64, maxL uint64) []uint64 {
+	selected := make([]uint64, maxL)
 
-		switch h.DelaunayType {
-		case deluanayTypeSimple:
-			resultSet := &distqueue.DistQueue{Size: first.FriendCountAtLevel(level), ClosestLast: true}
+	switch h.DelaunayType {
+	case deluanayTypeSimple:
+		resultSet := &distqueue.DistQueue{Size: uint64(len(candidates)), ClosestLast: true}
 
-			for _, n := range first.Friends[level].Nodes {
-				resultSet.Push(n, h.DistFunc(first.P, h.Nodes[n].P))
-			}
-			for resultSet.Len() > maxL {
-				resultSet.Pop()
-			}
-			// js: cleanup old reverse links
-			for _, oldFriend := range first.Friends[level].Nodes {
-				h.Nodes[oldFriend].RemoveReverseLink(first.GetNodeId(), level)
-			}
-			// FRIENDS ARE STORED IN DISTANCE ORDER, closest at index 0
-			first.Friends[level].Nodes = first.Friends[level].Nodes[0:maxL]
-			for i := maxL - 1; i >= 0; i-- {
-				item := resultSet.Pop()
-				first.Friends[level].Nodes[i] = item.Node
-				h.Nodes[item.Node].AddReverseLink(first.GetNodeId(), level)
-			}
+		for _, n := range candidates {
+			resultSet.Push(n, h.DistFunc(p, h.node(n).P))
+		}
+		for resultSet.Len() > maxL {
+			resultSet.Pop()
+		}
+		for i := maxL; i > 0; i-- {
+			selected[i-1] = resultSet.Pop().Node
+		}
 
-		case deluanayTypeHeuristic:
+	case deluanayTypeHeuristic:
+		resultSet := &distqueue.DistQueue{Size: uint64(len(candidates))}
 
-			resultSet := &distqueue.DistQueue{Size: first.FriendCountAtLevel(level)}
+		for _, n := range candidates {
+			resultSet.Push(n, h.DistFunc(p, h.node(n).P))
+		}
+		h.getNeighborsByHeuristic(resultSet, maxL, false)
 
-			for _, n := range first.Friends[level].Nodes {
-				resultSet.Push(n, h.DistFunc(first.P, h.Nodes[n].P))
-			}
-			h.getNeighborsByHeuristic(resultSet, maxL, false)
+		for i := uint64(0); i < maxL; i++ {
+			selected[i] = resultSet.Pop().Node
+		}
+	}
+	return selected
+}
 
-			// js: cleanup old reverse links
-			for _, oldFriend := range first.Friends[level].Nodes {
-				h.Nodes[oldFriend].RemoveReverseLink(first.GetNodeId(), level)
-			}
-			// FRIENDS ARE STORED IN DISTANCE ORDER, closest at index 0
-			first.Friends[level].Nodes = first.Friends[level].Nodes[0:maxL]
-			for i := uint64(0); i < maxL; i++ {
-				item := resultSet.Pop()
-				first.Friends[level].Nodes[i] = item.Node
-				h.Nodes[item.Node].AddReverseLink(first.GetNodeId(), level)
-			}
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
 	}
+	return true
 }
 
 func (h *Hnsw) getNeighborsByHeuristic(resultSet *distqueue.DistQueue, M uint64, last bool) {
@@ -113,7 +177,7 @@ func (h *Hnsw) getNeighborsByHeuristic(resultSet *distqueue.DistQueue, M uint64,
 		e := workSet.Pop()
 		good := true
 		for _, r := range result {
-			if h.DistFunc(h.Nodes[r.Node].P, h.Nodes[e.Node].P) < e.D {
+			if h.DistFunc(h.node(r.Node).P, h.node(e.Node).P) < e.D {
 				good = false
 				break
 			}
@@ -143,7 +207,49 @@ func (h *Hnsw) getNeighborsByHeuristic(resultSet *distqueue.DistQueue, M uint64,
 }
 
 func New(M uint64, efConstruction uint64, first framework.Point) *Hnsw {
+	h := newEmpty(M, efConstruction, uint64(len(first)))
 
+	// add first point, it will be our enterpoint (index 0)
+	firstnode := framework.NewNode(first, 0, 0)
+	h.Nodes[0] = firstnode
+	h.Enterpoint = uint64(0)
+
+	h.CountLevel[0] = 1
+	h.MaxLayer = 0
+	h.Sequence = 1
+
+	return h
+}
+
+// Metric selects the distance used by an index created with NewEmpty
+type Metric int
+
+const (
+	// L2 ranks points by squared euclidean distance
+	L2 Metric = iota
+	// Cosine ranks points by cosine similarity, points are L2-normalised as
+	// by NewNormalized
+	Cosine
+)
+
+// NewEmpty creates an index without nodes for points of dimension dim. The
+// first point added becomes the enterpoint, node ids start at 0.
+func NewEmpty(M uint64, efConstruction uint64, dim uint64, metric Metric) (*Hnsw, error) {
+	if dim == 0 {
+		return nil, errors.New("hnsw: dimension must be positive")
+	}
+	h := newEmpty(M, efConstruction, dim)
+	switch metric {
+	case L2:
+	case Cosine:
+		h.Normalize = true
+	default:
+		return nil, fmt.Errorf("hnsw: unknown metric %d", metric)
+	}
+	return h, nil
+}
+
+func newEmpty(M uint64, efConstruction uint64, dim uint64) *Hnsw {
 	h := Hnsw{}
 	h.M = M
 
@@ -152,69 +258,166 @@ func New(M uint64, efConstruction uint64, first framework.Point) *Hnsw {
 	h.EfConstruction = efConstruction
 	h.M0 = 2 * M
 	h.DelaunayType = deluanayTypeHeuristic
+	h.Dimension = dim
 
 	h.bitset = bitsetpool.New()
+	h.metrics = nopMetrics{}
 
 	//h.DistFunc = f32.L2Squared8AVX
 	h.DistFunc = f32.L2Squared
 
-	// add first point, it will be our enterpoint (index 0)
 	h.Nodes = make(map[uint64]*framework.Node)
-	firstnode := framework.NewNode(first, 0, 0)
-	h.Nodes[0] = firstnode
-	h.Enterpoint = uint64(0)
-
 	h.CountLevel = make(map[uint64]uint64)
-	h.CountLevel[0] = 1
-	h.MaxLayer = 0
-	h.Sequence = 1
 
 	return &h
 }
 
-func (h *Hnsw) findBestEnterPoint(ep *distqueue.Item, q framework.Point, curlevel uint64, maxLayer uint64) *distqueue.Item {
+func (h *Hnsw) findBestEnterPoint(s *search, ep *distqueue.Item, curlevel uint64, maxLayer uint64) *distqueue.Item {
+	friends := make([]uint64, 0, h.M)
 	for level := maxLayer; level > curlevel; level-- {
 		// js: start search at the least granular level
 		for changed := true; changed; {
 			changed = false
-			for _, n := range h.Nodes[ep.Node].GetNodeFriends(level) {
-				d := h.DistFunc(h.Nodes[n].P, q)
+			if !s.visit(level, ep.Node, ep.D) {
+				return ep
+			}
+			friends = h.friendsAt(ep.Node, level, friends[:0])
+			for _, n := range friends {
+				d, ok := s.distance(h, n)
+				if !ok {
+					return ep
+				}
 				if d < ep.D {
 					ep = &distqueue.Item{Node: n, D: d}
 					changed = true
 				}
 			}
+			if changed {
+				s.hops++
+			}
 		}
 	}
 
 	return ep
 }
 
+// Add inserts q and returns the id of the new node. It panics on invalid
+// points, use TryAdd to handle the errors.
 func (h *Hnsw) Add(q framework.Point) uint64 {
-	h.Lock()
-	defer h.Unlock()
+	indexForNewNode, err := h.TryAdd(q)
+	if err != nil {
+		panic(err)
+	}
+	return indexForNewNode
+}
 
+// AddContext is like Add but gives up searching for the neighbours of the
+// new node when ctx is done. Once the node is being linked into the graph
+// the insert runs to completion.
+func (h *Hnsw) AddContext(ctx context.Context, q framework.Point) (uint64, error) {
+	return h.addContext(ctx, q, nil, 0)
+}
+
+// addContext adds q with tags as part of document, 0 if it isn't part of one
+func (h *Hnsw) addContext(ctx context.Context, q framework.Point, tags []uint64, document uint64) (uint64, error) {
+	start := time.Now()
+	q, err := h.preprocess(q)
+	if err != nil {
+		return 0, err
+	}
+	h.rlock()
+	if h.empty() {
+		// the first node becomes the enterpoint, which needs the write lock
+		h.RUnlock()
+		h.lock()
+		defer h.Unlock()
+	} else {
+		defer h.RUnlock()
+	}
+
+	indexForNewNode := atomic.AddUint64(&h.Sequence, 1) - 1
+	newNode, top, err := h.prepare(ctx, q, indexForNewNode)
+	if err != nil {
+		return 0, err
+	}
+	if h.wal != nil {
+		if err := h.wal.logAdd(indexForNewNode, q, tags, document); err != nil {
+			return 0, err
+		}
+	}
+	newNode.Tags = append([]uint64(nil), tags...)
+	newNode.Document = document
+	h.publish(newNode, top)
+	h.tags.add(indexForNewNode, newNode.Tags)
+	h.documents.add(document, indexForNewNode)
+	h.reportLevel(newNode.Level)
+	h.metrics.ObserveAdd(time.Since(start))
+
+	return indexForNewNode, nil
+}
+
+// add inserts q with the given node id, the caller must hold a lock
+func (h *Hnsw) add(q framework.Point, indexForNewNode uint64, tags []uint64, document uint64) {
+	newNode, top, _ := h.prepare(context.Background(), q, indexForNewNode)
+	newNode.Tags = tags
+	newNode.Document = document
+	h.publish(newNode, top)
+	h.tags.add(indexForNewNode, tags)
+	h.documents.add(document, indexForNewNode)
+}
+
+// prepare creates a new node and finds its friends on every level up to
+// the returned top level. Nothing in the graph is modified.
+func (h *Hnsw) prepare(ctx context.Context, q framework.Point, indexForNewNode uint64) (*framework.Node, uint64, error) {
 	// generate random level
 	curlevel := uint64(math.Floor(-math.Log(rand.Float64() * h.LevelMult)))
 
-	currentMaxLayer := h.Nodes[h.Enterpoint].Level
-	ep := &distqueue.Item{Node: h.Enterpoint, D: h.DistFunc(h.Nodes[h.Enterpoint].P, q)}
-
-	indexForNewNode := h.Sequence
-	h.Sequence++
 	newNode := framework.NewNode(q, curlevel, indexForNewNode)
-	h.CountLevel[curlevel]++
+	if h.empty() {
+		// the caller holds the write lock, newNode becomes the enterpoint
+		newNode.AllocateFriendsUpTo(curlevel, h.M)
+		return newNode, curlevel, nil
+	}
+	friends, err := h.neighbours(newSearch(ctx, q, SearchOptions{}), curlevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	top := uint64(len(friends) - 1)
+	newNode.AllocateFriendsUpTo(top, h.M)
+	for level, ids := range friends {
+		newNode.Friends[uint64(level)].Nodes = ids
+	}
+
+	return newNode, top, nil
+}
+
+// neighbours finds the friends of a new node at s.q whose level is
+// curlevel. They are indexed by level, up to the lower of curlevel and the
+// current max layer, closest first.
+func (h *Hnsw) neighbours(s *search, curlevel uint64) ([][]uint64, error) {
+	enterpoint := atomic.LoadUint64(&h.Enterpoint)
+	epNode := h.node(enterpoint)
+	if epNode == nil {
+		// empty index
+		return nil, nil
+	}
+	currentMaxLayer := epNode.Level
+	ep := &distqueue.Item{Node: enterpoint, D: h.DistFunc(epNode.P, s.q)}
 
 	// first pass, find another ep if curlevel < maxLayer
-	ep = h.findBestEnterPoint(ep, q, curlevel, currentMaxLayer)
+	ep = h.findBestEnterPoint(s, ep, curlevel, currentMaxLayer)
 
 	// second pass, ef = efConstruction
 	// loop through every level from the new nodes level down to level 0
 	// create new connections in every layer
-	for level := min(curlevel, currentMaxLayer); level < math.MaxUint64; level-- { // note: level intentionally overflows/wraps here
+	top := min(curlevel, currentMaxLayer)
+	friends := make([][]uint64, top+1)
+	for level := top; level < math.MaxUint64; level-- { // note: level intentionally overflows/wraps here
 
 		resultSet := &distqueue.DistQueue{ClosestLast: true}
-		h.searchAtLayer(q, resultSet, h.EfConstruction, ep, level)
+		if err := h.searchAtLayer(s, resultSet, h.EfConstruction, ep, level); err != nil {
+			return nil, err
+		}
 		switch h.DelaunayType {
 		case deluanayTypeSimple:
 			// shrink resultSet to M closest elements (the simple heuristic)
@@ -224,52 +427,118 @@ func (h *Hnsw) Add(q framework.Point) uint64 {
 		case deluanayTypeHeuristic:
 			h.getNeighborsByHeuristic(resultSet, h.M, true)
 		}
-		newNode.AllocateFriendsUpTo(level, h.M) // js: potentially only needs to alloc this level
-		newNode.Friends[level].Nodes = make([]uint64, resultSet.Len())
+		friends[level] = make([]uint64, resultSet.Len())
 		for i := resultSet.Len() - 1; i < math.MaxUint64; i-- { // note: i intentionally overflows/wraps here
 			item := resultSet.Pop()
 			// store in order, closest at index 0
-			newNode.Friends[level].Nodes[i] = item.Node // HERE
-			h.Nodes[item.Node].AddReverseLink(indexForNewNode, level)
+			friends[level][i] = item.Node // HERE
+		}
+	}
+
+	return friends, nil
+}
+
+// publish links a node returned by prepare into the graph. The new node
+// only becomes visible once its neighbours link to it.
+func (h *Hnsw) publish(newNode *framework.Node, top uint64) {
+	indexForNewNode := newNode.Id
+	curlevel := newNode.Level
+
+	for {
+		seq := atomic.LoadUint64(&h.Sequence)
+		if indexForNewNode < seq || atomic.CompareAndSwapUint64(&h.Sequence, seq, indexForNewNode+1) {
+			break
 		}
 	}
 
-	// Add it and increase slice length if neccessary
+	// Add it to the map, no other node links to it yet
+	h.nodesLock.Lock()
 	h.Nodes[indexForNewNode] = newNode
+	h.CountLevel[curlevel]++
+	first := len(h.Nodes) == 1
+	h.nodesLock.Unlock()
 
 	// now add connections to newNode from newNodes neighbours (makes it visible in the graph)
-	for level := min(curlevel, currentMaxLayer); level < math.MaxUint64; level-- { // note: level intentionally overflows/wraps here
+	for level := top; level < math.MaxUint64; level-- { // note: level intentionally overflows/wraps here
 		for _, n := range newNode.Friends[level].Nodes {
-			h.link(h.Nodes[n], indexForNewNode, level)
+			h.addReverseLink(n, indexForNewNode, level)
+			h.link(n, indexForNewNode, level)
 		}
 	}
 
-	if curlevel > h.MaxLayer {
-		h.MaxLayer = curlevel
-		h.Enterpoint = indexForNewNode
+	h.epLock.Lock()
+	if first || curlevel > atomic.LoadUint64(&h.MaxLayer) {
+		atomic.StoreUint64(&h.MaxLayer, curlevel)
+		atomic.StoreUint64(&h.Enterpoint, indexForNewNode)
 	}
+	h.epLock.Unlock()
+}
 
-	return indexForNewNode
+// VectorReader is implemented by the readers in the io subpackage
+type VectorReader interface {
+	ReadVector() ([]float32, error)
+}
+
+// BulkLoad adds every vector returned by r until io.EOF and returns the
+// assigned node ids in input order
+func (h *Hnsw) BulkLoad(r VectorReader) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	for {
+		v, err := r.ReadVector()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return ids, err
+		}
+		id, err := h.TryAdd(v)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
 }
 
+// Remove deletes node indexToRemove, unknown ids are ignored. Removing the
+// last node leaves the index empty. It panics if the write-ahead log fails,
+// use TryRemove to handle the errors.
 func (h *Hnsw) Remove(indexToRemove uint64) {
-	h.Lock()
-	defer h.Unlock()
+	if err := h.TryRemove(indexToRemove); err != nil && err != ErrNotFound {
+		panic(err)
+	}
+}
 
-	hn := h.Nodes[indexToRemove]
+// remove deletes a node from the graph, the caller must hold the write lock
+func (h *Hnsw) remove(indexToRemove uint64) {
+	hn := h.mutable(indexToRemove)
+	for _, m := range hn.ReverseFriends {
+		for n := range m.Nodes {
+			h.mutable(n)
+		}
+	}
+	// node and Document read the maps without the outer lock
+	h.nodesLock.Lock()
 	delete(h.Nodes, indexToRemove)
+	h.CountLevel[hn.Level]--
+	h.nodesLock.Unlock()
+	h.tags.remove(indexToRemove, hn.Tags)
+	h.documents.remove(hn.Document, indexToRemove)
 
 	hn.UnlinkFromFriends(h.Nodes)
 
-	h.CountLevel[hn.Level]--
+	if len(h.Nodes) == 0 {
+		h.Enterpoint, h.MaxLayer = 0, 0
+		return
+	}
 
-	// Re-assign enterpoint
+	// Re-assign enterpoint to one of the nodes on the highest level
 	if h.Enterpoint == indexToRemove {
+	reassign:
 		for layer := h.MaxLayer; layer < math.MaxUint64; layer-- { //note: level intentionally overflows/wraps here
 			for i, nn := range h.Nodes {
 				if nn.Level == layer {
 					h.Enterpoint = i
-					break
+					break reassign
 				}
 			}
 		}
@@ -283,13 +552,12 @@ func (h *Hnsw) Remove(indexToRemove uint64) {
 			break
 		}
 	}
-
-	if h.Enterpoint == indexToRemove {
-		panic("failed to reassign enterpoint")
-	}
 }
 
-func (h *Hnsw) searchAtLayer(q framework.Point, resultSet *distqueue.DistQueue, efConstruction uint64, ep *distqueue.Item, level uint64) {
+// searchAtLayer collects the efConstruction nodes closest to q at level in
+// resultSet. If the search has to stop early, because its context is done
+// or its budget is used up, resultSet holds the nodes found so far.
+func (h *Hnsw) searchAtLayer(s *search, resultSet *distqueue.DistQueue, efConstruction uint64, ep *distqueue.Item, level uint64) error {
 	var pool, visited = h.bitset.Get()
 
 	candidates := &distqueue.DistQueue{Size: efConstruction * 3}
@@ -297,25 +565,43 @@ func (h *Hnsw) searchAtLayer(q framework.Point, resultSet *distqueue.DistQueue,
 	visited.Set(uint(ep.Node))
 	candidates.Push(ep.Node, ep.D)
 
-	resultSet.Push(ep.Node, ep.D)
+	if s.match(ep.Node) {
+		resultSet.Push(ep.Node, ep.D)
+	}
+
+	friends := make([]uint64, 0, h.M0)
+	for i := 0; candidates.Len() > 0; i++ {
+		if i%ctxCheckInterval == 0 && !s.checkContext() {
+			break
+		}
 
-	for candidates.Len() > 0 {
 		_, lowerBound := resultSet.Top() // worst distance so far
 		c := candidates.Pop()
 
-		if c.D > lowerBound {
+		if c.D > lowerBound && (s.filter == nil || resultSet.Len() >= efConstruction) {
 			// since candidates is sorted, it wont get any better...
 			break
 		}
 
-		if h.Nodes[c.Node].FriendLevelCount() >= level+1 {
-			friends := h.Nodes[c.Node].Friends[level].Nodes
+		if !s.visit(level, c.Node, c.D) {
+			break
+		}
+		friends = h.searchFriendsAt(c.Node, level, friends[:0])
+		if len(friends) > 0 {
 			for _, n := range friends {
 				if !visited.Test(uint(n)) {
 					visited.Set(uint(n))
-					d := h.DistFunc(q, h.Nodes[n].P)
+					d, ok := s.distance(h, n)
+					if !ok {
+						break
+					}
 					_, topD := resultSet.Top()
-					if resultSet.Len() < efConstruction {
+					if !s.match(n) {
+						// not a result, but it may lead to one
+						if resultSet.Len() < efConstruction || topD > d {
+							candidates.Push(n, d)
+						}
+					} else if resultSet.Len() < efConstruction {
 						item := resultSet.Push(n, d)
 						candidates.PushItem(item)
 					} else if topD > d {
@@ -327,24 +613,18 @@ func (h *Hnsw) searchAtLayer(q framework.Point, resultSet *distqueue.DistQueue,
 			}
 		}
 	}
+	s.candidates = candidates.Len()
 	h.bitset.Free(pool)
+	return s.err
 }
 
 func (h *Hnsw) Search(q framework.Point, ef uint64, K uint64) *distqueue.DistQueue {
-	h.RLock()
-	currentMaxLayer := h.MaxLayer
-	ep := &distqueue.Item{Node: h.Enterpoint, D: h.DistFunc(h.Nodes[h.Enterpoint].P, q)}
-
-	resultSet := &distqueue.DistQueue{Size: ef + 1, ClosestLast: true}
-
-	// first pass, find best ep
-	ep = h.findBestEnterPoint(ep, q, 0, currentMaxLayer)
-
-	h.searchAtLayer(q, resultSet, ef, ep, 0)
-	h.RUnlock()
-
-	for resultSet.Len() > K {
-		resultSet.Pop()
-	}
+	resultSet, _ := h.SearchContext(context.Background(), q, ef, K)
 	return resultSet
 }
+
+// SearchContext is like Search but stops when ctx is done. In that case the
+// K best nodes found so far are returned together with the context error.
+func (h *Hnsw) SearchContext(ctx context.Context, q framework.Point, ef uint64, K uint64) (*distqueue.DistQueue, error) {
+	return h.SearchWithOptions(ctx, q, ef, K, SearchOptions{})
+}
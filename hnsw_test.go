@@ -1,6 +1,9 @@
 package hnsw
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -12,6 +15,7 @@ import (
 	"time"
 
 	"github.com/jnmly/go-hnsw/framework"
+	hnswio "github.com/jnmly/go-hnsw/io"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -167,9 +171,18 @@ func newHnsw() *Hnsw {
 		cfgEfConstruction = 2000
 	)
 
-	var zero framework.Point = make([]float32, dimsize)
-	h := New(cfgM, cfgEfConstruction, zero)
+	h, err := NewEmpty(cfgM, cfgEfConstruction, dimsize, L2)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
 
+func newSmallHnsw() *Hnsw {
+	h, err := NewEmpty(16, 100, dimsize, L2)
+	if err != nil {
+		panic(err)
+	}
 	return h
 }
 
@@ -303,6 +316,66 @@ func TestLocking(t *testing.T) {
 	wg.Wait()
 }
 
+// TestLockingStress runs inserts concurrently with searches, removals,
+// snapshots and stats, run it with -race
+func TestLockingStress(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	wg := &sync.WaitGroup{}
+
+	// the searches need a non-empty index, this point is never removed
+	h.Add(vecs[0])
+
+	const workers = 8
+	ids := make(chan uint64, len(vecs))
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			for i := 1 + w; i < len(vecs); i += workers {
+				ids <- h.Add(vecs[i])
+			}
+			wg.Done()
+		}(w)
+		go func(w int) {
+			for i := w; i < len(vecs); i += workers {
+				result := h.Search(vecs[i], 50, 5)
+				assert.True(t, result.Len() > 0)
+			}
+			wg.Done()
+		}(w)
+	}
+
+	wg.Add(2)
+	go func() {
+		for i := 0; i < 20; i++ {
+			h.Remove(<-ids)
+		}
+		wg.Done()
+	}()
+	go func() {
+		for i := 0; i < 5; i++ {
+			h.Stats()
+			assert.NoError(t, h.Save(ioutil.Discard))
+		}
+		wg.Done()
+	}()
+	wg.Wait()
+
+	assert.Equal(t, len(vecs)-20, len(h.Nodes))
+	assert.NotNil(t, h.Nodes[h.Enterpoint])
+	for id, n := range h.Nodes {
+		for level, friends := range n.Friends {
+			for _, f := range friends.Nodes {
+				friend := h.Nodes[f]
+				if !assert.NotNil(t, friend) {
+					continue
+				}
+				assert.True(t, friend.ReverseFriends[level].Nodes[id], "missing reverse link %d -> %d", f, id)
+			}
+		}
+	}
+}
+
 func TestLocalMaximum(t *testing.T) {
 	t.SkipNow()
 	var zero framework.Point = make([]float32, 2)
@@ -358,3 +431,325 @@ func TestLocalMaximum(t *testing.T) {
 		assert.Equal(t, uint64(1), item.Node)
 	}
 }
+
+func TestBulkLoad(t *testing.T) {
+	h := newHnsw()
+	q, vecs := getTestdata(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, hnswio.WriteAll(hnswio.NewFvecsWriter(buf), vecs))
+
+	ids, err := h.BulkLoad(hnswio.NewFvecsReader(buf))
+	assert.NoError(t, err)
+	assert.Equal(t, len(vecs), len(ids))
+	assert.Equal(t, len(vecs), len(h.Nodes))
+
+	for i, id := range ids {
+		assert.Equal(t, vecs[i], h.Nodes[id].P)
+	}
+
+	Search(h, q)
+}
+
+func TestSearchContext(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	res, err := h.SearchContext(context.Background(), q, 100, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = h.SearchContext(ctx, q, 100, 10)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, res.Len() > 0 && res.Len() <= 10)
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	_, err = h.SearchContext(ctx, q, 100, 10)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestAddContext(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:100] {
+		h.Add(v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := h.AddContext(ctx, vecs[100])
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 100, len(h.Nodes))
+
+	id, err := h.AddContext(context.Background(), vecs[100])
+	assert.NoError(t, err)
+	assert.Equal(t, vecs[100], h.Nodes[id].P)
+	assert.Equal(t, 101, len(h.Nodes))
+}
+
+func TestSearchBudget(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	res, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{MaxDistanceComputations: 1000000, MaxVisited: 1000000})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Len())
+
+	res, err = h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{MaxDistanceComputations: 20})
+	assert.Equal(t, ErrBudgetExhausted, err)
+	assert.True(t, res.Len() > 0 && res.Len() <= 10)
+
+	res, err = h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{MaxVisited: 3})
+	assert.Equal(t, ErrBudgetExhausted, err)
+	assert.True(t, res.Len() > 0 && res.Len() <= 10)
+}
+
+func TestSearchStats(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	var stats SearchStats
+	var path []uint64
+	trace := func(level uint64, node uint64, d float32) {
+		path = append(path, node)
+	}
+	_, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Stats: &stats, Trace: trace})
+	assert.NoError(t, err)
+
+	assert.True(t, stats.DistanceComputations > 0)
+	assert.True(t, len(stats.VisitedPerLayer) > 0)
+	var visited uint64
+	for _, n := range stats.VisitedPerLayer {
+		visited += n
+	}
+	assert.Equal(t, uint64(len(path)), visited)
+	assert.Equal(t, h.Enterpoint, path[0])
+}
+
+func TestStatsStruct(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	stats := h.StatsStruct()
+	assert.Equal(t, 300, stats.Nodes)
+	assert.Equal(t, h.MaxLayer+1, uint64(len(stats.Levels)))
+	total := uint64(0)
+	for _, l := range stats.Levels {
+		assert.Equal(t, l.CountLevel, l.Nodes)
+		assert.True(t, float64(l.MinDegree) <= l.AvgDegree && l.AvgDegree <= float64(l.MaxDegree))
+		total += l.Nodes
+	}
+	assert.Equal(t, uint64(300), total)
+	assert.True(t, stats.Levels[0].MaxDegree <= int(h.M0))
+	// the top level of every node counts too
+	for level := range stats.Levels {
+		maxDegree := 0
+		for _, n := range h.Nodes {
+			if l, ok := n.Friends[uint64(level)]; ok && len(l.Nodes) > maxDegree {
+				maxDegree = len(l.Nodes)
+			}
+		}
+		assert.Equal(t, maxDegree, stats.Levels[level].MaxDegree, "level %d", level)
+	}
+	assert.Equal(t, uint64(300*dimsize*4), stats.MemoryData)
+
+	// a node nobody links to
+	h.Nodes[1000] = framework.NewNode(vecs[300], 0, 1000)
+	h.CountLevel[0]++
+	after := h.StatsStruct()
+	assert.Equal(t, stats.Orphans+1, after.Orphans)
+	assert.Equal(t, stats.Unreachable+1, after.Unreachable)
+	assert.Contains(t, h.Stats(), "Number of nodes: 301")
+}
+
+func TestReachability(t *testing.T) {
+	// New puts its first point on level 0 only, the nodes added later all
+	// have upper levels
+	h := New(16, 100, make([]float32, dimsize))
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+	h.Reconnect()
+	assert.Equal(t, 0, h.Reachability().Count())
+
+	// cut every link to a node on level 0
+	var u uint64
+	for id, n := range h.Nodes {
+		if n.Level == 0 && id != h.Enterpoint {
+			u = id
+			break
+		}
+	}
+	for _, n := range h.Nodes {
+		if n.FriendLevelCount() == 0 {
+			continue
+		}
+		friends := n.Friends[0].Nodes[:0]
+		for _, f := range n.Friends[0].Nodes {
+			if f != u {
+				friends = append(friends, f)
+			}
+		}
+		n.Friends[0].Nodes = friends
+	}
+	delete(h.Nodes[u].ReverseFriends, 0)
+
+	r := h.Reachability()
+	assert.Contains(t, r.Unreachable[0], u)
+
+	assert.True(t, h.Reconnect() > 0)
+	assert.Equal(t, 0, h.Reachability().Count())
+	res := h.Search(h.Nodes[u].P, 50, 1)
+	assert.Equal(t, u, res.Pop().Node)
+}
+
+func TestMerge(t *testing.T) {
+	_, vecs := getTestdata(t)
+	a := newSmallHnsw()
+	for _, v := range vecs[:150] {
+		a.Add(v)
+	}
+	b := newSmallHnsw()
+	for _, v := range vecs[150:300] {
+		b.Add(v)
+	}
+	aState, bState := FullState(a), FullState(b)
+
+	h, err := Merge(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, aState, FullState(a))
+	assert.Equal(t, bState, FullState(b))
+
+	assert.Equal(t, 300, len(h.Nodes))
+	assert.Equal(t, a.Sequence+b.Sequence, h.Sequence)
+	count := uint64(0)
+	for _, c := range h.CountLevel {
+		count += c
+	}
+	assert.Equal(t, uint64(300), count)
+	assert.Equal(t, max(a.MaxLayer, b.MaxLayer), h.MaxLayer)
+	assert.Equal(t, h.MaxLayer, h.Nodes[h.Enterpoint].Level)
+
+	for id, n := range h.Nodes {
+		for level, friends := range n.Friends {
+			for _, f := range friends.Nodes {
+				friend := h.Nodes[f]
+				if !assert.NotNil(t, friend) {
+					continue
+				}
+				assert.True(t, friend.ReverseFriends[level].Nodes[id], "missing reverse link %d -> %d", f, id)
+			}
+		}
+	}
+
+	// nodes of both graphs can be found
+	found := 0
+	for i, v := range vecs[:300] {
+		id := uint64(i)
+		if i >= 150 {
+			id = a.Sequence + uint64(i-150)
+		}
+		assert.Equal(t, v, h.Nodes[id].P)
+		if h.Search(v, 50, 1).Pop().Node == id {
+			found++
+		}
+	}
+	assert.True(t, found > 290, "found %d", found)
+
+	// the merged index can be extended
+	id := h.Add(vecs[300])
+	assert.Equal(t, a.Sequence+b.Sequence, id)
+
+	// an index can be merged with itself
+	h, err = Merge(a, a)
+	assert.NoError(t, err)
+	assert.Equal(t, 300, len(h.Nodes))
+
+	c, err := NewEmpty(16, 100, dimsize/2, L2)
+	assert.NoError(t, err)
+	c.Add(vecs[0][:dimsize/2])
+	_, err = Merge(a, c)
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	c, err = NewEmpty(16, 100, dimsize, Cosine)
+	assert.NoError(t, err)
+	_, err = Merge(c, a)
+	assert.Equal(t, ErrIncompatible, err)
+}
+
+func TestEmpty(t *testing.T) {
+	_, err := NewEmpty(4, 100, 0, L2)
+	assert.Error(t, err)
+
+	h, err := NewEmpty(4, 100, 2, L2)
+	assert.NoError(t, err)
+	_, err = h.TrySearch([]float32{1, 0}, 10, 1)
+	assert.Equal(t, ErrEmptyIndex, err)
+	_, err = h.TryAdd([]float32{1})
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+
+	// the first point becomes the enterpoint
+	first, err := h.TryAdd([]float32{1, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), first)
+	assert.Equal(t, first, h.Enterpoint)
+	for i := 1; i < 100; i++ {
+		_, err := h.TryAdd([]float32{float32(i), float32(i % 7)})
+		assert.NoError(t, err)
+	}
+	res := h.Search([]float32{1, 0}, 100, 1)
+	assert.Equal(t, first, res.Pop().Node)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.Save(buf))
+	g, err := Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, len(g.Nodes))
+
+	// removing every node returns the index to its empty state
+	for id := range h.Nodes {
+		h.Remove(id)
+	}
+	assert.Equal(t, 0, len(h.Nodes))
+	assert.Equal(t, uint64(0), h.MaxLayer)
+	_, err = h.TrySearch([]float32{1, 0}, 10, 1)
+	assert.Equal(t, ErrEmptyIndex, err)
+	id, err := h.TryAdd([]float32{3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, id, h.Enterpoint)
+	res = h.Search([]float32{0, 0}, 10, 10)
+	assert.Equal(t, uint64(1), res.Len())
+
+	// an empty index survives a save and load
+	h.Remove(id)
+	buf.Reset()
+	assert.NoError(t, h.Save(buf))
+	g, err = Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), g.Dimension)
+	_, err = g.TryAdd([]float32{1, 1})
+	assert.NoError(t, err)
+
+	c, err := NewEmpty(4, 100, 2, Cosine)
+	assert.NoError(t, err)
+	assert.True(t, c.Normalize)
+	_, err = c.TryAdd([]float32{0, 0})
+	assert.Error(t, err)
+	_, err = NewEmpty(4, 100, 2, Metric(7))
+	assert.Error(t, err)
+}
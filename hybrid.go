@@ -0,0 +1,138 @@
+package hnsw
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// SparseScorer returns the keyword or attribute score of a node, higher is
+// better. BM25 scores can be used as they are, the scores are normalised
+// over the candidates before they are fused.
+type SparseScorer func(id uint64) float32
+
+// Fusion selects how HybridSearch combines the vector and sparse rankings
+type Fusion int
+
+const (
+	// WeightedSum adds the min-max normalised vector similarity and sparse
+	// score of each candidate
+	WeightedSum Fusion = iota
+	// ReciprocalRankFusion adds 1 / (RankConstant + rank) of each candidate
+	// in the vector and the sparse ranking
+	ReciprocalRankFusion
+)
+
+// HybridOptions configures HybridSearch
+type HybridOptions struct {
+	Fusion Fusion
+	// VectorWeight and SparseWeight weigh the two rankings, both zero
+	// weighs them equally
+	VectorWeight float32
+	SparseWeight float32
+	// RankConstant is the constant of ReciprocalRankFusion, defaults to 60
+	RankConstant float32
+	// Candidates is the number of results taken from Search before fusing,
+	// defaults to ef
+	Candidates uint64
+}
+
+type hybridCandidate struct {
+	id     uint64
+	d      float32
+	sparse float32
+	score  float32
+}
+
+func (h *Hnsw) HybridSearch(q framework.Point, ef uint64, K uint64, scorer SparseScorer, opts HybridOptions) *distqueue.DistQueue {
+	resultSet, _ := h.HybridSearchContext(context.Background(), q, ef, K, scorer, opts)
+	return resultSet
+}
+
+// HybridSearchContext fuses the candidates of a vector search with the
+// scores of scorer and returns the K best. The result is ordered like the
+// one of Search, Pop returns the worst item first, but D holds the negated
+// fused score instead of a distance.
+func (h *Hnsw) HybridSearchContext(ctx context.Context, q framework.Point, ef uint64, K uint64, scorer SparseScorer, opts HybridOptions) (*distqueue.DistQueue, error) {
+	n := opts.Candidates
+	if n == 0 {
+		n = ef
+	}
+	vectorWeight, sparseWeight := opts.VectorWeight, opts.SparseWeight
+	if vectorWeight == 0 && sparseWeight == 0 {
+		vectorWeight, sparseWeight = 1, 1
+	}
+
+	found, err := h.SearchContext(ctx, q, max(ef, n), n)
+
+	// candidates ends up in vector ranking order, closest first
+	candidates := make([]hybridCandidate, found.Len())
+	for i := len(candidates) - 1; i >= 0; i-- {
+		item := found.Pop()
+		candidates[i] = hybridCandidate{id: item.Node, d: item.D, sparse: scorer(item.Node)}
+	}
+
+	switch opts.Fusion {
+	case ReciprocalRankFusion:
+		k := opts.RankConstant
+		if k == 0 {
+			k = 60
+		}
+		for i := range candidates {
+			candidates[i].score = vectorWeight / (k + float32(i+1))
+		}
+		bySparse := make([]int, len(candidates))
+		for i := range bySparse {
+			bySparse[i] = i
+		}
+		sort.SliceStable(bySparse, func(i, j int) bool {
+			return candidates[bySparse[i]].sparse > candidates[bySparse[j]].sparse
+		})
+		for rank, i := range bySparse {
+			candidates[i].score += sparseWeight / (k + float32(rank+1))
+		}
+	default:
+		minD, maxD := minMax(candidates, func(c hybridCandidate) float32 { return c.d })
+		minS, maxS := minMax(candidates, func(c hybridCandidate) float32 { return c.sparse })
+		for i, c := range candidates {
+			// the closest candidate has similarity 1, the farthest 0
+			candidates[i].score = vectorWeight*minMaxScale(maxD-c.d, 0, maxD-minD) + sparseWeight*minMaxScale(c.sparse, minS, maxS)
+		}
+	}
+
+	resultSet := &distqueue.DistQueue{Size: K + 1, ClosestLast: true}
+	for _, c := range candidates {
+		resultSet.Push(c.id, -c.score)
+		if resultSet.Len() > K {
+			resultSet.Pop()
+		}
+	}
+	return resultSet, err
+}
+
+func minMax(candidates []hybridCandidate, value func(hybridCandidate) float32) (float32, float32) {
+	if len(candidates) == 0 {
+		return 0, 0
+	}
+	lo, hi := value(candidates[0]), value(candidates[0])
+	for _, c := range candidates[1:] {
+		v := value(c)
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
+
+// minMaxScale maps v from [lo, hi] to [0, 1], all equal values map to 1
+func minMaxScale(v, lo, hi float32) float32 {
+	if hi == lo {
+		return 1
+	}
+	return (v - lo) / (hi - lo)
+}
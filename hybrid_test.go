@@ -0,0 +1,45 @@
+package hnsw
+
+import (
+	"testing"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHybridSearch(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	ranking := func(res *distqueue.DistQueue) []uint64 {
+		ids := make([]uint64, res.Len())
+		for i := len(ids) - 1; i >= 0; i-- {
+			ids[i] = res.Pop().Node
+		}
+		return ids
+	}
+	vector := ranking(h.Search(q, 100, 20))
+	boosted := vector[len(vector)-1]
+	scorer := func(id uint64) float32 {
+		if id == boosted {
+			return 10
+		}
+		return 0
+	}
+
+	// without sparse weight the vector ranking is kept
+	res := ranking(h.HybridSearch(q, 100, 10, scorer, HybridOptions{VectorWeight: 1, Candidates: 20}))
+	assert.Equal(t, vector[:10], res)
+
+	res = ranking(h.HybridSearch(q, 100, 10, scorer, HybridOptions{SparseWeight: 2, VectorWeight: 1, Candidates: 20}))
+	assert.Equal(t, boosted, res[0])
+	assert.Len(t, res, 10)
+
+	res = ranking(h.HybridSearch(q, 100, 5, scorer, HybridOptions{Fusion: ReciprocalRankFusion, RankConstant: 1, Candidates: 20}))
+	assert.Len(t, res, 5)
+	assert.Contains(t, res, boosted)
+	assert.Equal(t, vector[0], res[0])
+}
@@ -0,0 +1,60 @@
+package io
+
+import (
+	"encoding/csv"
+	goio "io"
+	"strconv"
+	"strings"
+)
+
+// CSVReader reads one vector per CSV record
+type CSVReader struct {
+	r *csv.Reader
+}
+
+func NewCSVReader(r goio.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	return &CSVReader{r: cr}
+}
+
+func (cr *CSVReader) ReadVector() ([]float32, error) {
+	record, err := cr.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]float32, len(record))
+	for i, s := range record {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
+
+// CSVWriter writes one vector per CSV record
+type CSVWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func NewCSVWriter(w goio.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) WriteVector(v []float32) error {
+	cw.record = cw.record[:0]
+	for _, f := range v {
+		cw.record = append(cw.record, strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	return cw.w.Write(cw.record)
+}
+
+// Flush writes any buffered data to the underlying writer
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,55 @@
+// Package io implements streaming readers and writers for the vector file
+// formats commonly used with ANN datasets: fvecs, bvecs and ivecs (TEXMEX),
+// NumPy .npy float32 arrays and CSV.
+package io
+
+import (
+	"errors"
+	goio "io"
+)
+
+// upper bound for the dimension of a vector, protects against huge
+// allocations on a corrupt header
+const maxDim = 1 << 20
+
+var (
+	// ErrFormat is returned when the input is not in the expected format
+	ErrFormat = errors.New("io: invalid vector file format")
+	// ErrRange is returned when a component can't be represented in the output format
+	ErrRange = errors.New("io: value out of range")
+)
+
+// VectorReader returns one vector per call and io.EOF once the input is exhausted
+type VectorReader interface {
+	ReadVector() ([]float32, error)
+}
+
+// VectorWriter writes one vector per call
+type VectorWriter interface {
+	WriteVector(v []float32) error
+}
+
+// ReadAll reads vectors from r until io.EOF
+func ReadAll(r VectorReader) ([][]float32, error) {
+	vecs := make([][]float32, 0)
+	for {
+		v, err := r.ReadVector()
+		if err == goio.EOF {
+			return vecs, nil
+		}
+		if err != nil {
+			return vecs, err
+		}
+		vecs = append(vecs, v)
+	}
+}
+
+// WriteAll writes every vector in vecs to w
+func WriteAll(w VectorWriter, vecs [][]float32) error {
+	for _, v := range vecs {
+		if err := w.WriteVector(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	goio "io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomVecs(n int, dim int) [][]float32 {
+	vecs := make([][]float32, n)
+	for i := range vecs {
+		vecs[i] = make([]float32, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = float32(rand.Intn(256))
+		}
+	}
+	return vecs
+}
+
+func TestRoundTrip(t *testing.T) {
+	vecs := randomVecs(50, 12)
+
+	formats := map[string]struct {
+		writer func(w goio.Writer) VectorWriter
+		reader func(r goio.Reader) VectorReader
+	}{
+		"fvecs": {
+			func(w goio.Writer) VectorWriter { return NewFvecsWriter(w) },
+			func(r goio.Reader) VectorReader { return NewFvecsReader(r) },
+		},
+		"bvecs": {
+			func(w goio.Writer) VectorWriter { return NewBvecsWriter(w) },
+			func(r goio.Reader) VectorReader { return NewBvecsReader(r) },
+		},
+		"ivecs": {
+			func(w goio.Writer) VectorWriter { return NewIvecsWriter(w) },
+			func(r goio.Reader) VectorReader { return NewIvecsReader(r) },
+		},
+		"csv": {
+			func(w goio.Writer) VectorWriter { return NewCSVWriter(w) },
+			func(r goio.Reader) VectorReader { return NewCSVReader(r) },
+		},
+	}
+
+	for name, f := range formats {
+		buf := &bytes.Buffer{}
+		w := f.writer(buf)
+		assert.NoError(t, WriteAll(w, vecs), name)
+		if cw, ok := w.(*CSVWriter); ok {
+			assert.NoError(t, cw.Flush())
+		}
+
+		read, err := ReadAll(f.reader(buf))
+		assert.NoError(t, err, name)
+		assert.Equal(t, vecs, read, name)
+	}
+}
+
+func TestNpy(t *testing.T) {
+	vecs := randomVecs(7, 5)
+	vecs[0][0] = 0.25
+
+	buf := &bytes.Buffer{}
+	w, err := NewNpyWriter(buf, len(vecs), 5)
+	assert.NoError(t, err)
+	assert.NoError(t, WriteAll(w, vecs))
+	assert.NoError(t, w.Close())
+	assert.Error(t, w.WriteVector(vecs[0]))
+
+	// data starts on a 64 byte boundary
+	assert.Equal(t, 0, (buf.Len()-len(vecs)*5*4)%64)
+
+	r, err := NewNpyReader(buf)
+	assert.NoError(t, err)
+	rows, cols := r.Shape()
+	assert.Equal(t, 7, rows)
+	assert.Equal(t, 5, cols)
+
+	read, err := ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, vecs, read)
+}
+
+func TestNpyUnsupported(t *testing.T) {
+	header := "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 2), }\n"
+	buf := &bytes.Buffer{}
+	buf.Write(npyMagic)
+	buf.Write([]byte{1, 0, byte(len(header)), 0})
+	buf.WriteString(header)
+
+	_, err := NewNpyReader(buf)
+	assert.Error(t, err)
+
+	_, err = NewNpyReader(bytes.NewBufferString("not a numpy file"))
+	assert.Equal(t, ErrFormat, err)
+}
+
+func TestNpyMalformed(t *testing.T) {
+	for _, shape := range []string{"(2, -3)", "(-1, 3)", "(2, 2000000)", "(99999999999999999999, 3)", "(2, 3, 4)"} {
+		header := "{'descr': '<f4', 'fortran_order': False, 'shape': " + shape + ", }\n"
+		buf := &bytes.Buffer{}
+		buf.Write(npyMagic)
+		buf.Write([]byte{1, 0, byte(len(header)), 0})
+		buf.WriteString(header)
+
+		_, err := NewNpyReader(buf)
+		assert.Error(t, err, shape)
+	}
+
+	// a version 2 header claiming 4GB
+	buf := bytes.NewBuffer(npyMagic)
+	buf.Write([]byte{2, 0, 0xff, 0xff, 0xff, 0xff})
+	_, err := NewNpyReader(buf)
+	assert.Equal(t, ErrFormat, err)
+}
+
+func TestVecsMalformed(t *testing.T) {
+	for _, dim := range []uint32{0x80000000, 1 << 30} {
+		header := make([]byte, 4)
+		binary.LittleEndian.PutUint32(header, dim)
+		_, err := NewFvecsReader(bytes.NewReader(header)).ReadVector()
+		assert.Equal(t, ErrFormat, err)
+		_, err = NewBvecsReader(bytes.NewReader(header)).ReadVector()
+		assert.Equal(t, ErrFormat, err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewFvecsWriter(buf).WriteVector([]float32{1, 2, 3}))
+
+	r := NewFvecsReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+	_, err := r.ReadVector()
+	assert.Equal(t, goio.ErrUnexpectedEOF, err)
+}
+
+func TestBvecsRange(t *testing.T) {
+	w := NewBvecsWriter(&bytes.Buffer{})
+	assert.Equal(t, ErrRange, w.WriteVector([]float32{1, 300}))
+}
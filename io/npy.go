@@ -0,0 +1,187 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var npyMagic = []byte("\x93NUMPY")
+
+// NpyReader reads the rows of a 2-d little-endian float32 NumPy array stored
+// in a .npy file. A 1-d array is returned as a single vector.
+type NpyReader struct {
+	r    *bufio.Reader
+	rows int
+	cols int
+	read int
+	buf  []byte
+}
+
+var (
+	npyDescr   = regexp.MustCompile(`'descr':\s*'([^']*)'`)
+	npyFortran = regexp.MustCompile(`'fortran_order':\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`'shape':\s*\(([^)]*)\)`)
+)
+
+func NewNpyReader(r goio.Reader) (*NpyReader, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(npyMagic)+2)
+	if _, err := goio.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic[:len(npyMagic)], npyMagic) {
+		return nil, ErrFormat
+	}
+
+	var headerLen int
+	switch magic[len(npyMagic)] {
+	case 1:
+		var l uint16
+		if err := binary.Read(br, binary.LittleEndian, &l); err != nil {
+			return nil, err
+		}
+		headerLen = int(l)
+	case 2, 3:
+		var l uint32
+		if err := binary.Read(br, binary.LittleEndian, &l); err != nil {
+			return nil, err
+		}
+		headerLen = int(l)
+	default:
+		return nil, ErrFormat
+	}
+
+	// real headers are a few hundred bytes
+	if headerLen > maxDim {
+		return nil, ErrFormat
+	}
+	header := make([]byte, headerLen)
+	if _, err := goio.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+
+	descr := npyDescr.FindSubmatch(header)
+	if descr == nil || (string(descr[1]) != "<f4" && string(descr[1]) != "f4") {
+		return nil, fmt.Errorf("io: unsupported npy dtype, only little-endian float32 is supported")
+	}
+	fortran := npyFortran.FindSubmatch(header)
+	if fortran == nil || string(fortran[1]) != "False" {
+		return nil, fmt.Errorf("io: fortran ordered npy arrays are not supported")
+	}
+	shape := npyShape.FindSubmatch(header)
+	if shape == nil {
+		return nil, ErrFormat
+	}
+
+	dims := make([]int, 0, 2)
+	for _, s := range strings.Split(string(shape[1]), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		d, err := strconv.Atoi(s)
+		if err != nil || d < 0 {
+			return nil, ErrFormat
+		}
+		dims = append(dims, d)
+	}
+
+	nr := &NpyReader{r: br}
+	switch len(dims) {
+	case 1:
+		nr.rows, nr.cols = 1, dims[0]
+	case 2:
+		nr.rows, nr.cols = dims[0], dims[1]
+	default:
+		return nil, fmt.Errorf("io: npy array must be 1-d or 2-d, got %d dimensions", len(dims))
+	}
+	if nr.cols > maxDim {
+		return nil, ErrFormat
+	}
+	nr.buf = make([]byte, 4*nr.cols)
+	return nr, nil
+}
+
+// Shape returns the number of rows and the dimension of the array
+func (nr *NpyReader) Shape() (int, int) {
+	return nr.rows, nr.cols
+}
+
+func (nr *NpyReader) ReadVector() ([]float32, error) {
+	if nr.read >= nr.rows {
+		return nil, goio.EOF
+	}
+	if _, err := goio.ReadFull(nr.r, nr.buf); err != nil {
+		if err == goio.EOF {
+			return nil, goio.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	nr.read++
+	v := make([]float32, nr.cols)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(nr.buf[4*i:]))
+	}
+	return v, nil
+}
+
+// NpyWriter writes a 2-d float32 NumPy array. The shape is part of the file
+// header, so the number of rows has to be known when the writer is created.
+type NpyWriter struct {
+	w       goio.Writer
+	rows    int
+	cols    int
+	written int
+	buf     []byte
+}
+
+func NewNpyWriter(w goio.Writer, rows int, cols int) (*NpyWriter, error) {
+	header := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }", rows, cols)
+	// magic, version and header length take 10 bytes, the header is padded
+	// with spaces and terminated by a newline to align the data to 64 bytes
+	total := 10 + len(header) + 1
+	header += strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	buf := &bytes.Buffer{}
+	buf.Write(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &NpyWriter{w: w, rows: rows, cols: cols, buf: make([]byte, 4*cols)}, nil
+}
+
+func (nw *NpyWriter) WriteVector(v []float32) error {
+	if len(v) != nw.cols {
+		return fmt.Errorf("io: npy row has %d values, expected %d", len(v), nw.cols)
+	}
+	if nw.written >= nw.rows {
+		return fmt.Errorf("io: npy array already has %d rows", nw.rows)
+	}
+	for i := range v {
+		binary.LittleEndian.PutUint32(nw.buf[4*i:], math.Float32bits(v[i]))
+	}
+	if _, err := nw.w.Write(nw.buf); err != nil {
+		return err
+	}
+	nw.written++
+	return nil
+}
+
+// Close checks that all rows announced in the header have been written
+func (nw *NpyWriter) Close() error {
+	if nw.written != nw.rows {
+		return fmt.Errorf("io: npy array has %d rows, %d were written", nw.rows, nw.written)
+	}
+	return nil
+}
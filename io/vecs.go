@@ -0,0 +1,211 @@
+package io
+
+import (
+	"bufio"
+	"encoding/binary"
+	goio "io"
+	"math"
+)
+
+// The TEXMEX formats store every vector as a little-endian int32 dimension
+// followed by the components: float32 for fvecs, uint8 for bvecs and int32
+// for ivecs.
+
+type vecsReader struct {
+	r    *bufio.Reader
+	size int
+	buf  []byte
+}
+
+func newVecsReader(r goio.Reader, size int) vecsReader {
+	return vecsReader{r: bufio.NewReader(r), size: size}
+}
+
+// VecsDim returns the dimension stored in the 4 byte header of a vector in
+// one of the TEXMEX formats, ErrFormat if it is negative or implausibly large
+func VecsDim(header []byte) (int, error) {
+	dim := int(int32(binary.LittleEndian.Uint32(header)))
+	if dim < 0 || dim > maxDim {
+		return 0, ErrFormat
+	}
+	return dim, nil
+}
+
+// next returns the raw bytes of the next vector and its dimension
+func (vr *vecsReader) next() ([]byte, int, error) {
+	var header [4]byte
+	if _, err := goio.ReadFull(vr.r, header[:]); err != nil {
+		return nil, 0, err
+	}
+	dim, err := VecsDim(header[:])
+	if err != nil {
+		return nil, 0, err
+	}
+	if cap(vr.buf) < dim*vr.size {
+		vr.buf = make([]byte, dim*vr.size)
+	}
+	buf := vr.buf[:dim*vr.size]
+	if _, err := goio.ReadFull(vr.r, buf); err != nil {
+		if err == goio.EOF {
+			return nil, 0, goio.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	return buf, dim, nil
+}
+
+// FvecsReader reads float32 vectors in the fvecs format
+type FvecsReader struct {
+	vecsReader
+}
+
+func NewFvecsReader(r goio.Reader) *FvecsReader {
+	return &FvecsReader{newVecsReader(r, 4)}
+}
+
+func (fr *FvecsReader) ReadVector() ([]float32, error) {
+	buf, dim, err := fr.next()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
+
+// BvecsReader reads uint8 vectors in the bvecs format and returns them as float32
+type BvecsReader struct {
+	vecsReader
+}
+
+func NewBvecsReader(r goio.Reader) *BvecsReader {
+	return &BvecsReader{newVecsReader(r, 1)}
+}
+
+func (br *BvecsReader) ReadVector() ([]float32, error) {
+	buf, dim, err := br.next()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = float32(buf[i])
+	}
+	return v, nil
+}
+
+// IvecsReader reads int32 vectors in the ivecs format, as used for ground truth files
+type IvecsReader struct {
+	vecsReader
+}
+
+func NewIvecsReader(r goio.Reader) *IvecsReader {
+	return &IvecsReader{newVecsReader(r, 4)}
+}
+
+func (ir *IvecsReader) ReadInts() ([]int32, error) {
+	buf, dim, err := ir.next()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]int32, dim)
+	for i := range v {
+		v[i] = int32(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
+
+func (ir *IvecsReader) ReadVector() ([]float32, error) {
+	ints, err := ir.ReadInts()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]float32, len(ints))
+	for i := range ints {
+		v[i] = float32(ints[i])
+	}
+	return v, nil
+}
+
+type vecsWriter struct {
+	w   goio.Writer
+	buf []byte
+}
+
+func (vw *vecsWriter) write(dim int, size int, put func(buf []byte)) error {
+	n := 4 + dim*size
+	if cap(vw.buf) < n {
+		vw.buf = make([]byte, n)
+	}
+	buf := vw.buf[:n]
+	binary.LittleEndian.PutUint32(buf, uint32(dim))
+	put(buf[4:])
+	_, err := vw.w.Write(buf)
+	return err
+}
+
+// FvecsWriter writes float32 vectors in the fvecs format
+type FvecsWriter struct {
+	vecsWriter
+}
+
+func NewFvecsWriter(w goio.Writer) *FvecsWriter {
+	return &FvecsWriter{vecsWriter{w: w}}
+}
+
+func (fw *FvecsWriter) WriteVector(v []float32) error {
+	return fw.write(len(v), 4, func(buf []byte) {
+		for i := range v {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v[i]))
+		}
+	})
+}
+
+// BvecsWriter writes vectors in the bvecs format, components are truncated to uint8
+type BvecsWriter struct {
+	vecsWriter
+}
+
+func NewBvecsWriter(w goio.Writer) *BvecsWriter {
+	return &BvecsWriter{vecsWriter{w: w}}
+}
+
+func (bw *BvecsWriter) WriteVector(v []float32) error {
+	for _, x := range v {
+		if x < 0 || x > math.MaxUint8 {
+			return ErrRange
+		}
+	}
+	return bw.write(len(v), 1, func(buf []byte) {
+		for i := range v {
+			buf[i] = uint8(v[i])
+		}
+	})
+}
+
+// IvecsWriter writes int32 vectors in the ivecs format
+type IvecsWriter struct {
+	vecsWriter
+}
+
+func NewIvecsWriter(w goio.Writer) *IvecsWriter {
+	return &IvecsWriter{vecsWriter{w: w}}
+}
+
+func (iw *IvecsWriter) WriteInts(v []int32) error {
+	return iw.write(len(v), 4, func(buf []byte) {
+		for i := range v {
+			binary.LittleEndian.PutUint32(buf[4*i:], uint32(v[i]))
+		}
+	})
+}
+
+func (iw *IvecsWriter) WriteVector(v []float32) error {
+	ints := make([]int32, len(v))
+	for i := range v {
+		ints[i] = int32(v[i])
+	}
+	return iw.WriteInts(ints)
+}
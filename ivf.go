@@ -0,0 +1,221 @@
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// Ivf is a two level index for large collections. Points are assigned to
+// the nearest of a set of k-means centroids, which are indexed by a small
+// Hnsw, and kept in a posting list per centroid. A search scans the lists
+// of the nprobe centroids nearest to the query.
+type Ivf struct {
+	sync.RWMutex
+
+	centroids *Hnsw
+	ef        uint64
+	lists     map[uint64][]*framework.Posting
+	// assignment maps point ids to their centroid
+	assignment map[uint64]uint64
+	sequence   uint64
+}
+
+// TrainIvf clusters sample into k centroids with the given number of
+// k-means iterations. The centroids are indexed with New(M, efConstruction),
+// centroid i becomes node i of that index.
+func TrainIvf(sample [][]float32, k int, iterations int, M uint64, efConstruction uint64) (*Ivf, error) {
+	if k <= 0 || len(sample) < k {
+		return nil, errors.New("hnsw: not enough points to train the centroids")
+	}
+
+	centroids := make([]framework.Point, k)
+	for i, j := range rand.Perm(len(sample))[:k] {
+		centroids[i] = append(framework.Point(nil), sample[j]...)
+	}
+
+	h := newCentroidIndex(centroids, M, efConstruction)
+	for it := 0; it < iterations; it++ {
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for _, p := range sample {
+			c, err := nearestCentroid(h, p, efConstruction)
+			if err != nil {
+				return nil, err
+			}
+			if sums[c] == nil {
+				sums[c] = make([]float64, len(p))
+			}
+			for d, v := range p {
+				sums[c][d] += float64(v)
+			}
+			counts[c]++
+		}
+		for c := range centroids {
+			// empty clusters keep their centroid
+			if counts[c] == 0 {
+				continue
+			}
+			for d := range centroids[c] {
+				centroids[c][d] = float32(sums[c][d] / float64(counts[c]))
+			}
+		}
+		h = newCentroidIndex(centroids, M, efConstruction)
+	}
+
+	return &Ivf{
+		centroids:  h,
+		ef:         efConstruction,
+		lists:      make(map[uint64][]*framework.Posting),
+		assignment: make(map[uint64]uint64),
+	}, nil
+}
+
+// newCentroidIndex indexes centroids so that centroid i is node i
+func newCentroidIndex(centroids []framework.Point, M uint64, efConstruction uint64) *Hnsw {
+	h := New(M, efConstruction, centroids[0])
+	for _, c := range centroids[1:] {
+		h.Add(c)
+	}
+	return h
+}
+
+func nearestCentroid(h *Hnsw, q framework.Point, ef uint64) (uint64, error) {
+	res, err := h.SearchContext(context.Background(), q, ef, 1)
+	if err != nil {
+		return 0, err
+	}
+	if res.Len() == 0 {
+		return 0, ErrEmptyIndex
+	}
+	return res.Pop().Node, nil
+}
+
+// Centroids returns the index of the centroids
+func (ivf *Ivf) Centroids() *Hnsw {
+	return ivf.centroids
+}
+
+// Add assigns q to its nearest centroid and returns the id of the point. It
+// panics on invalid points, use TryAdd to handle the errors.
+func (ivf *Ivf) Add(q framework.Point) uint64 {
+	id, err := ivf.TryAdd(q)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// TryAdd is like Add but returns ErrDimensionMismatch or ErrNaN for invalid
+// points
+func (ivf *Ivf) TryAdd(q framework.Point) (uint64, error) {
+	c, err := nearestCentroid(ivf.centroids, q, ivf.ef)
+	if err != nil {
+		return 0, err
+	}
+
+	ivf.Lock()
+	defer ivf.Unlock()
+	id := ivf.sequence
+	ivf.sequence++
+	ivf.lists[c] = append(ivf.lists[c], &framework.Posting{Id: id, Centroid: c, P: q})
+	ivf.assignment[id] = c
+	return id, nil
+}
+
+// Remove deletes the point with the given id, unknown ids are ignored
+func (ivf *Ivf) Remove(id uint64) {
+	ivf.Lock()
+	defer ivf.Unlock()
+
+	c, ok := ivf.assignment[id]
+	if !ok {
+		return
+	}
+	delete(ivf.assignment, id)
+	list := ivf.lists[c]
+	for i, p := range list {
+		if p.Id == id {
+			list[i] = list[len(list)-1]
+			ivf.lists[c] = list[:len(list)-1]
+			break
+		}
+	}
+}
+
+// Search returns the K points closest to q out of the posting lists of the
+// nprobe centroids nearest to q, in the same order as Hnsw.Search
+func (ivf *Ivf) Search(q framework.Point, nprobe uint64, K uint64) *distqueue.DistQueue {
+	probes := ivf.centroids.Search(q, max(ivf.ef, nprobe), nprobe)
+
+	resultSet := &distqueue.DistQueue{Size: K + 1, ClosestLast: true}
+	ivf.RLock()
+	defer ivf.RUnlock()
+	for probes.Len() > 0 {
+		for _, p := range ivf.lists[probes.Pop().Node] {
+			d := ivf.centroids.DistFunc(q, p.P)
+			if resultSet.Len() < K {
+				resultSet.Push(p.Id, d)
+			} else if _, topD := resultSet.Top(); d < topD {
+				resultSet.PopAndPush(p.Id, d)
+			}
+		}
+	}
+	return resultSet
+}
+
+// Save writes the centroids and posting lists to w
+func (ivf *Ivf) Save(w io.Writer) error {
+	ivf.RLock()
+	fi := framework.Ivf{
+		Centroids: &ivf.centroids.Hnsw,
+		Sequence:  ivf.sequence,
+	}
+	for _, list := range ivf.lists {
+		fi.Postings = append(fi.Postings, list...)
+	}
+	data, err := fi.Marshal()
+	ivf.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadIvf reads an index previously written by Ivf.Save. Searches of the
+// centroids use efConstruction of the centroid index as ef.
+func LoadIvf(r io.Reader) (*Ivf, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var fi framework.Ivf
+	if err := fi.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	if fi.Centroids == nil {
+		return nil, errors.New("hnsw: ivf index without centroids")
+	}
+
+	centroids := &Hnsw{Hnsw: *fi.Centroids}
+	centroids.init()
+	ivf := &Ivf{
+		centroids:  centroids,
+		ef:         centroids.EfConstruction,
+		lists:      make(map[uint64][]*framework.Posting),
+		assignment: make(map[uint64]uint64, len(fi.Postings)),
+		sequence:   fi.Sequence,
+	}
+	for _, p := range fi.Postings {
+		ivf.lists[p.Centroid] = append(ivf.lists[p.Centroid], p)
+		ivf.assignment[p.Id] = p.Centroid
+	}
+	return ivf, nil
+}
@@ -0,0 +1,72 @@
+package hnsw
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIvf(t *testing.T) {
+	q, vecs := getTestdata(t)
+	vecs = vecs[:500]
+
+	ivf, err := TrainIvf(vecs, 16, 5, 8, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 16, len(ivf.Centroids().Nodes))
+
+	for i, v := range vecs {
+		assert.Equal(t, uint64(i), ivf.Add(v))
+	}
+
+	// probing all lists is exact
+	exact := &distqueue.DistQueue{ClosestLast: true}
+	for i, v := range vecs {
+		exact.Push(uint64(i), ivf.Centroids().DistFunc(q, v))
+		if exact.Len() > 10 {
+			exact.Pop()
+		}
+	}
+	res := ivf.Search(q, 16, 10)
+	assert.Equal(t, uint64(10), res.Len())
+	for res.Len() > 0 {
+		assert.Equal(t, exact.Pop().Node, res.Pop().Node)
+	}
+
+	// a single probe finds the point itself
+	for i, v := range vecs[:50] {
+		assert.Equal(t, uint64(i), ivf.Search(v, 1, 1).Pop().Node)
+	}
+
+	ivf.Remove(3)
+	res = ivf.Search(vecs[3], 16, 1)
+	assert.NotEqual(t, uint64(3), res.Pop().Node)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, ivf.Save(buf))
+	loaded, err := LoadIvf(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, FullState(ivf.Centroids()), FullState(loaded.Centroids()))
+	assert.Equal(t, uint64(500), loaded.Add(vecs[0]))
+	for i, v := range vecs[4:50] {
+		assert.Equal(t, uint64(i+4), loaded.Search(v, 2, 1).Pop().Node)
+	}
+
+	_, err = TrainIvf(vecs[:3], 16, 1, 8, 50)
+	assert.Error(t, err)
+
+	// invalid points are rejected, not assigned
+	_, err = loaded.TryAdd(vecs[0][:3])
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	nan := append([]float32(nil), vecs[0]...)
+	nan[0] = float32(math.NaN())
+	_, err = loaded.TryAdd(nan)
+	assert.Equal(t, ErrNaN, err)
+	assert.Panics(t, func() { loaded.Add(nan) })
+	id, err := loaded.TryAdd(vecs[1])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(501), id)
+}
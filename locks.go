@@ -0,0 +1,89 @@
+package hnsw
+
+import (
+	"sync"
+
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// number of locks the friend lists of the nodes are spread over
+const nodeLockStripes = 1024
+
+func (h *Hnsw) nodeLock(id uint64) *sync.RWMutex {
+	return &h.locks[id%nodeLockStripes]
+}
+
+// node returns the node with the given id or nil. Points never change once
+// a node is added, so n.P can be used without holding any lock.
+func (h *Hnsw) node(id uint64) *framework.Node {
+	h.nodesLock.RLock()
+	n := h.Nodes[id]
+	h.nodesLock.RUnlock()
+	return n
+}
+
+// empty reports whether the index holds no nodes
+func (h *Hnsw) empty() bool {
+	h.nodesLock.RLock()
+	n := len(h.Nodes)
+	h.nodesLock.RUnlock()
+	return n == 0
+}
+
+// nodeList returns all nodes currently in the index
+func (h *Hnsw) nodeList() []*framework.Node {
+	h.nodesLock.RLock()
+	nodes := make([]*framework.Node, 0, len(h.Nodes))
+	for _, n := range h.Nodes {
+		nodes = append(nodes, n)
+	}
+	h.nodesLock.RUnlock()
+	return nodes
+}
+
+// friendsAt appends the friends of node id at level to buf
+func (h *Hnsw) friendsAt(id uint64, level uint64, buf []uint64) []uint64 {
+	n := h.node(id)
+	if n == nil {
+		return buf
+	}
+	l := h.nodeLock(id)
+	l.RLock()
+	buf = append(buf, n.GetNodeFriends(level)...)
+	l.RUnlock()
+	return buf
+}
+
+// searchFriendsAt is like friendsAt but only returns friends if the node
+// has been linked above level, as expected by searchAtLayer
+func (h *Hnsw) searchFriendsAt(id uint64, level uint64, buf []uint64) []uint64 {
+	n := h.node(id)
+	if n == nil {
+		return buf
+	}
+	l := h.nodeLock(id)
+	l.RLock()
+	if n.FriendLevelCount() >= level+1 {
+		buf = append(buf, n.Friends[level].Nodes...)
+	}
+	l.RUnlock()
+	return buf
+}
+
+func (h *Hnsw) addReverseLink(id uint64, other uint64, level uint64) {
+	n := h.node(id)
+	l := h.nodeLock(id)
+	l.Lock()
+	h.preserve(id, n)
+	n.AddReverseLink(other, level)
+	l.Unlock()
+}
+
+func (h *Hnsw) removeReverseLink(id uint64, other uint64, level uint64) {
+	n := h.node(id)
+	l := h.nodeLock(id)
+	l.Lock()
+	h.preserve(id, n)
+	n.RemoveReverseLink(other, level)
+	l.Unlock()
+}
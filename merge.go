@@ -0,0 +1,145 @@
+package hnsw
+
+import (
+	"context"
+	"fmt"
+	"unsafe"
+
+	"github.com/jnmly/go-hnsw/bitsetpool"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// Merge returns a new index holding the nodes of a and b, which are left
+// unchanged. The nodes of a keep their ids, the ids of b are shifted by
+// a.Sequence. Every node is linked to the friends it would get in the other
+// graph, found by searching it like an insert does. The result uses the
+// parameters and distance function of a. Indexes of different dimensions
+// or with a different transform or normalisation can't be merged.
+func Merge(a, b *Hnsw) (*Hnsw, error) {
+	// lock in a fixed order, so concurrent Merge(a, b) and Merge(b, a)
+	// don't wait for each other behind pending writers
+	first, second := a, b
+	if uintptr(unsafe.Pointer(b)) < uintptr(unsafe.Pointer(a)) {
+		first, second = b, a
+	}
+	first.RLock()
+	defer first.RUnlock()
+	if second != first {
+		second.RLock()
+		defer second.RUnlock()
+	}
+
+	dim, err := mergedDimension(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if a.Normalize != b.Normalize || !a.Transform.Equal(b.Transform) {
+		return nil, ErrIncompatible
+	}
+
+	offset := a.Sequence
+
+	h := &Hnsw{}
+	h.M = a.M
+	h.M0 = a.M0
+	h.EfConstruction = a.EfConstruction
+	h.DelaunayType = a.DelaunayType
+	h.LevelMult = a.LevelMult
+	h.DistFunc = a.DistFunc
+	h.Transform = a.Transform
+	h.Normalize = a.Normalize
+	h.Dimension = dim
+	h.bitset = bitsetpool.New()
+	h.metrics = nopMetrics{}
+	h.Sequence = a.Sequence + b.Sequence
+
+	h.Nodes = make(map[uint64]*framework.Node, len(a.Nodes)+len(b.Nodes))
+	for id, n := range a.Nodes {
+		h.Nodes[id] = copyNode(n, 0)
+	}
+	for id, n := range b.Nodes {
+		h.Nodes[id+offset] = copyNode(n, offset)
+	}
+
+	h.CountLevel = make(map[uint64]uint64)
+	for id, n := range h.Nodes {
+		h.CountLevel[n.Level]++
+		h.tags.add(id, n.Tags)
+		h.documents.add(n.Document, id)
+	}
+	h.MaxLayer, h.Enterpoint = a.MaxLayer, a.Enterpoint
+	if len(a.Nodes) == 0 || len(b.Nodes) > 0 && b.MaxLayer > a.MaxLayer {
+		h.MaxLayer, h.Enterpoint = b.MaxLayer, b.Enterpoint+offset
+	}
+
+	// search both graphs before linking, so every node is only matched
+	// against the original nodes of the other graph
+	type crossLinks struct {
+		id      uint64
+		friends [][]uint64
+	}
+	links := make([]crossLinks, 0, len(h.Nodes))
+	for id, n := range a.Nodes {
+		friends, _ := b.neighbours(newSearch(context.Background(), n.P, SearchOptions{}), n.Level)
+		for _, ids := range friends {
+			for i := range ids {
+				ids[i] += offset
+			}
+		}
+		links = append(links, crossLinks{id: id, friends: friends})
+	}
+	for id, n := range b.Nodes {
+		friends, _ := a.neighbours(newSearch(context.Background(), n.P, SearchOptions{}), n.Level)
+		links = append(links, crossLinks{id: id + offset, friends: friends})
+	}
+
+	for _, l := range links {
+		for level, ids := range l.friends {
+			for _, f := range ids {
+				h.link(l.id, f, uint64(level))
+			}
+		}
+	}
+
+	return h, nil
+}
+
+// mergedDimension returns the dimension of the points of a and b, which is
+// only known from the nodes for indexes created by New
+func mergedDimension(a, b *Hnsw) (uint64, error) {
+	dimension := func(h *Hnsw) uint64 {
+		if n, ok := h.Nodes[h.Enterpoint]; ok && h.Dimension == 0 {
+			return uint64(len(n.P))
+		}
+		return h.Dimension
+	}
+	da, db := dimension(a), dimension(b)
+	if da != 0 && db != 0 && da != db {
+		return 0, fmt.Errorf("%w: merging dimension %d into %d", ErrDimensionMismatch, db, da)
+	}
+	return max(da, db), nil
+}
+
+// copyNode returns a deep copy of n with offset added to all node ids
+func copyNode(n *framework.Node, offset uint64) *framework.Node {
+	p := make(framework.Point, len(n.P))
+	copy(p, n.P)
+	c := framework.NewNode(p, n.Level, n.Id+offset)
+	c.Tags = append([]uint64(nil), n.Tags...)
+	c.Document = n.Document
+	for level, l := range n.Friends {
+		ids := make([]uint64, len(l.Nodes), cap(l.Nodes))
+		for i, id := range l.Nodes {
+			ids[i] = id + offset
+		}
+		c.Friends[level] = &framework.LinkList{Nodes: ids}
+	}
+	for level, m := range n.ReverseFriends {
+		r := &framework.LinkMap{Nodes: make(map[uint64]bool, len(m.Nodes))}
+		for id := range m.Nodes {
+			r.Nodes[id+offset] = true
+		}
+		c.ReverseFriends[level] = r
+	}
+	return c
+}
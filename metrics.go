@@ -0,0 +1,72 @@
+package hnsw
+
+import "time"
+
+// Metrics receives measurements of index operations. Implementations must be
+// safe for concurrent use, see the prometheus package for an adapter.
+type Metrics interface {
+	// ObserveAdd, ObserveRemove and ObserveSearch record operation latency
+	ObserveAdd(d time.Duration)
+	ObserveRemove(d time.Duration)
+	ObserveSearch(d time.Duration)
+	// ObserveLockWait records the time spent acquiring the index lock
+	ObserveLockWait(d time.Duration)
+	// SetNodes reports the number of nodes in the index
+	SetNodes(n int)
+	// SetLevelCount reports the number of nodes whose top level is level
+	SetLevelCount(level uint64, n uint64)
+	// SetBitsetPoolSize reports the number of bitsets allocated for searches
+	SetBitsetPoolSize(n int)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveAdd(time.Duration)      {}
+func (nopMetrics) ObserveRemove(time.Duration)   {}
+func (nopMetrics) ObserveSearch(time.Duration)   {}
+func (nopMetrics) ObserveLockWait(time.Duration) {}
+func (nopMetrics) SetNodes(int)                  {}
+func (nopMetrics) SetLevelCount(uint64, uint64)  {}
+func (nopMetrics) SetBitsetPoolSize(int)         {}
+
+// SetMetrics reports measurements of the index to m, nil disables them
+func (h *Hnsw) SetMetrics(m Metrics) {
+	if m == nil {
+		m = nopMetrics{}
+	}
+	h.Lock()
+	defer h.Unlock()
+	h.metrics = m
+
+	m.SetNodes(len(h.Nodes))
+	for level, n := range h.CountLevel {
+		m.SetLevelCount(level, n)
+	}
+	m.SetBitsetPoolSize(h.bitset.Size())
+}
+
+// rlock takes the read lock and records how long that took
+func (h *Hnsw) rlock() {
+	start := time.Now()
+	h.RLock()
+	h.metrics.ObserveLockWait(time.Since(start))
+}
+
+// lock takes the write lock and records how long that took
+func (h *Hnsw) lock() {
+	start := time.Now()
+	h.Lock()
+	h.metrics.ObserveLockWait(time.Since(start))
+}
+
+// reportLevel updates the gauges after a node with the given top level has
+// been added or removed
+func (h *Hnsw) reportLevel(level uint64) {
+	h.nodesLock.RLock()
+	nodes, count := len(h.Nodes), h.CountLevel[level]
+	h.nodesLock.RUnlock()
+
+	h.metrics.SetNodes(nodes)
+	h.metrics.SetLevelCount(level, count)
+	h.metrics.SetBitsetPoolSize(h.bitset.Size())
+}
@@ -0,0 +1,50 @@
+package hnsw
+
+import (
+	"math"
+
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// NewNormalized is like New but L2-normalises every point added to or
+// searched in the index, including first. Squared L2 distances between unit
+// vectors rank like cosine similarity. Zero vectors are rejected.
+func NewNormalized(M uint64, efConstruction uint64, first framework.Point) (*Hnsw, error) {
+	p, err := unitVector(first)
+	if err != nil {
+		return nil, err
+	}
+	h := New(M, efConstruction, p)
+	h.Normalize = true
+	return h, nil
+}
+
+// unitVector returns a unit length copy of q
+func unitVector(q framework.Point) (framework.Point, error) {
+	var sum float64
+	for _, x := range q {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return nil, ErrZeroVector
+	}
+	norm := float32(math.Sqrt(sum))
+	p := make(framework.Point, len(q))
+	for i, x := range q {
+		p[i] = x / norm
+	}
+	return p, nil
+}
+
+// preprocess validates q and applies the transform and normalisation of
+// the index to it
+func (h *Hnsw) preprocess(q framework.Point) (framework.Point, error) {
+	if err := h.validate(q); err != nil {
+		return nil, err
+	}
+	q, err := applyTransform(h.Transform, q)
+	if err != nil || !h.Normalize {
+		return q, err
+	}
+	return unitVector(q)
+}
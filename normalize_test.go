@@ -0,0 +1,67 @@
+package hnsw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalized(t *testing.T) {
+	q, vecs := getTestdata(t)
+	first := make([]float32, dimsize)
+	first[0] = 1
+
+	_, err := NewNormalized(16, 100, make([]float32, dimsize))
+	assert.Error(t, err)
+
+	h, err := NewNormalized(16, 100, first)
+	assert.NoError(t, err)
+	for _, v := range vecs[:300] {
+		// scaling a vector doesn't change its direction
+		scaled := append([]float32(nil), v...)
+		for i := range scaled {
+			scaled[i] *= 3
+		}
+		_, err := h.AddContext(context.Background(), scaled)
+		assert.NoError(t, err)
+	}
+	for _, n := range h.Nodes {
+		var norm float64
+		for _, x := range n.P {
+			norm += float64(x) * float64(x)
+		}
+		assert.InDelta(t, 1, norm, 1e-4)
+	}
+	// the caller's vector is left alone
+	assert.Equal(t, float32(1), first[0])
+
+	_, err = h.AddContext(context.Background(), make([]float32, dimsize))
+	assert.True(t, errors.Is(err, ErrZeroVector))
+	_, err = h.AddContext(context.Background(), make([]float32, dimsize+1))
+	assert.Error(t, err)
+	_, err = h.SearchContext(context.Background(), make([]float32, dimsize), 100, 10)
+	assert.Error(t, err)
+
+	// unnormalised queries find the same nodes as normalised ones
+	unit := h.Search(q, 100, 10)
+	scaled := append([]float32(nil), q...)
+	for i := range scaled {
+		scaled[i] *= 0.5
+	}
+	res := h.Search(scaled, 100, 10)
+	for res.Len() > 0 {
+		assert.Equal(t, unit.Pop().Node, res.Pop().Node)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.Save(buf))
+	g, err := Load(buf)
+	assert.NoError(t, err)
+	assert.True(t, g.Normalize)
+	assert.Equal(t, uint64(dimsize), g.Dimension)
+	_, err = g.AddContext(context.Background(), make([]float32, dimsize))
+	assert.True(t, errors.Is(err, ErrZeroVector))
+}
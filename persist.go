@@ -0,0 +1,100 @@
+package hnsw
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jnmly/go-hnsw/bitsetpool"
+	"github.com/jnmly/go-hnsw/f32"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// Save writes a snapshot of the index to w. Writers are only blocked while
+// the snapshot is taken, not while it is written.
+func (h *Hnsw) Save(w io.Writer) error {
+	s := h.Snapshot()
+	defer s.Close()
+	_, err := s.WriteTo(w)
+	return err
+}
+
+// Load reads an index previously written by Save
+func Load(r io.Reader) (*Hnsw, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Hnsw{}
+	if err := h.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	h.init()
+	return h, nil
+}
+
+// init sets up the parts of an unmarshaled index which aren't serialised
+func (h *Hnsw) init() {
+	h.DistFunc = f32.L2Squared
+	h.bitset = bitsetpool.New()
+	h.metrics = nopMetrics{}
+
+	// empty maps are not serialised, make sure they can be written to
+	if h.Nodes == nil {
+		h.Nodes = make(map[uint64]*framework.Node)
+	}
+	if h.CountLevel == nil {
+		h.CountLevel = make(map[uint64]uint64)
+	}
+	for _, n := range h.Nodes {
+		// indexes saved before the dimension was recorded
+		if h.Dimension == 0 {
+			h.Dimension = uint64(len(n.P))
+		}
+		if n.Friends == nil {
+			n.Friends = make(map[uint64]*framework.LinkList)
+		}
+		if n.ReverseFriends == nil {
+			n.ReverseFriends = make(map[uint64]*framework.LinkMap)
+		}
+		h.tags.add(n.Id, n.Tags)
+		h.documents.add(n.Document, n.Id)
+	}
+}
+
+// writeSnapshot atomically replaces filename with the contents of s
+func writeSnapshot(s *Snapshot, filename string) error {
+	tmp := filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if _, err := s.WriteTo(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+func loadSnapshot(filename string) (*Hnsw, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
@@ -0,0 +1,89 @@
+// Package prometheus exports the metrics of an hnsw index to Prometheus.
+//
+//	m := prometheus.New("hnsw")
+//	registry.MustRegister(m)
+//	h.SetMetrics(m)
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// latency buckets from 10µs to about 2.6s
+var buckets = prom.ExponentialBuckets(0.00001, 4, 10)
+
+// Metrics implements hnsw.Metrics and prometheus.Collector
+type Metrics struct {
+	add      prom.Histogram
+	remove   prom.Histogram
+	search   prom.Histogram
+	lockWait prom.Histogram
+	nodes    prom.Gauge
+	levels   *prom.GaugeVec
+	bitsets  prom.Gauge
+}
+
+// New creates the metrics of one index, all names are prefixed by namespace
+func New(namespace string) *Metrics {
+	histogram := func(name string, help string) prom.Histogram {
+		return prom.NewHistogram(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		})
+	}
+	return &Metrics{
+		add:      histogram("add_duration_seconds", "Latency of Add."),
+		remove:   histogram("remove_duration_seconds", "Latency of Remove."),
+		search:   histogram("search_duration_seconds", "Latency of Search."),
+		lockWait: histogram("lock_wait_seconds", "Time spent waiting for the index lock."),
+		nodes: prom.NewGauge(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "nodes",
+			Help:      "Number of nodes in the index.",
+		}),
+		levels: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "level_nodes",
+			Help:      "Number of nodes by top level.",
+		}, []string{"level"}),
+		bitsets: prom.NewGauge(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "bitset_pool_size",
+			Help:      "Number of bitsets allocated for searches.",
+		}),
+	}
+}
+
+func (m *Metrics) collectors() []prom.Collector {
+	return []prom.Collector{m.add, m.remove, m.search, m.lockWait, m.nodes, m.levels, m.bitsets}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) ObserveAdd(d time.Duration)      { m.add.Observe(d.Seconds()) }
+func (m *Metrics) ObserveRemove(d time.Duration)   { m.remove.Observe(d.Seconds()) }
+func (m *Metrics) ObserveSearch(d time.Duration)   { m.search.Observe(d.Seconds()) }
+func (m *Metrics) ObserveLockWait(d time.Duration) { m.lockWait.Observe(d.Seconds()) }
+func (m *Metrics) SetNodes(n int)                  { m.nodes.Set(float64(n)) }
+func (m *Metrics) SetBitsetPoolSize(n int)         { m.bitsets.Set(float64(n)) }
+
+func (m *Metrics) SetLevelCount(level uint64, n uint64) {
+	m.levels.WithLabelValues(strconv.FormatUint(level, 10)).Set(float64(n))
+}
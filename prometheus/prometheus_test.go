@@ -0,0 +1,52 @@
+package prometheus
+
+import (
+	"math/rand"
+	"testing"
+
+	hnsw "github.com/jnmly/go-hnsw"
+	"github.com/jnmly/go-hnsw/framework"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("hnsw")
+	registry := prom.NewRegistry()
+	assert.NoError(t, registry.Register(m))
+
+	var zero framework.Point = make([]float32, 8)
+	h := hnsw.New(8, 50, zero)
+	h.SetMetrics(m)
+
+	for i := 0; i < 50; i++ {
+		p := make(framework.Point, 8)
+		for j := range p {
+			p[j] = rand.Float32()
+		}
+		h.Add(p)
+	}
+	h.Search(zero, 10, 5)
+	h.Remove(1)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	found := make(map[string]bool)
+	for _, f := range families {
+		found[f.GetName()] = true
+		switch f.GetName() {
+		case "hnsw_nodes":
+			assert.Equal(t, float64(50), f.GetMetric()[0].GetGauge().GetValue())
+		case "hnsw_add_duration_seconds":
+			assert.Equal(t, uint64(50), f.GetMetric()[0].GetHistogram().GetSampleCount())
+		case "hnsw_search_duration_seconds":
+			assert.Equal(t, uint64(1), f.GetMetric()[0].GetHistogram().GetSampleCount())
+		case "hnsw_remove_duration_seconds":
+			assert.Equal(t, uint64(1), f.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	for _, name := range []string{"hnsw_nodes", "hnsw_level_nodes", "hnsw_lock_wait_seconds", "hnsw_bitset_pool_size"} {
+		assert.True(t, found[name], name)
+	}
+}
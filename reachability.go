@@ -0,0 +1,151 @@
+package hnsw
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+)
+
+// Reachability lists the nodes a search starting at the enterpoint can't
+// reach, see Hnsw.Reachability
+type Reachability struct {
+	// Unreachable is indexed by level and holds the ids of the nodes which
+	// exist on that level but can't be reached on it, sorted
+	Unreachable [][]uint64
+}
+
+// Count returns the number of unreachable nodes on level 0, these can never
+// be returned by Search
+func (r Reachability) Count() int {
+	if len(r.Unreachable) == 0 {
+		return 0
+	}
+	return len(r.Unreachable[0])
+}
+
+// Reachability runs a breadth first search from the enterpoint on every
+// level. A search enters a level at the nodes it reached on the level
+// above, so those seed the search on the level below.
+func (h *Hnsw) Reachability() Reachability {
+	h.RLock()
+	defer h.RUnlock()
+
+	reachable := h.reachablePerLevel()
+	r := Reachability{Unreachable: make([][]uint64, len(reachable))}
+	for _, n := range h.nodeList() {
+		for level := uint64(0); level <= n.Level && level < uint64(len(reachable)); level++ {
+			if !reachable[level][n.Id] {
+				r.Unreachable[level] = append(r.Unreachable[level], n.Id)
+			}
+		}
+	}
+	for _, ids := range r.Unreachable {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return r
+}
+
+// reachablePerLevel returns the reachable nodes, indexed by level
+func (h *Hnsw) reachablePerLevel() []map[uint64]bool {
+	maxLayer := atomic.LoadUint64(&h.MaxLayer)
+	enterpoint := atomic.LoadUint64(&h.Enterpoint)
+
+	reachable := make([]map[uint64]bool, maxLayer+1)
+	queue := []uint64{enterpoint}
+	for level := maxLayer; level < maxLayer+1; level-- { // note: level intentionally overflows/wraps here
+		seen := make(map[uint64]bool, len(queue))
+		for _, id := range queue {
+			seen[id] = true
+		}
+		queue = h.bfs(seen, queue, level)
+		reachable[level] = seen
+	}
+	return reachable
+}
+
+// bfs adds the nodes reachable from queue on level to seen and returns all
+// nodes it visited, starting with queue
+func (h *Hnsw) bfs(seen map[uint64]bool, queue []uint64, level uint64) []uint64 {
+	var friends []uint64
+	for i := 0; i < len(queue); i++ {
+		friends = h.followedFriends(queue[i], level, friends[:0])
+		for _, f := range friends {
+			if !seen[f] {
+				seen[f] = true
+				queue = append(queue, f)
+			}
+		}
+	}
+	return queue
+}
+
+// followedFriends appends the friends of id a search follows on level to
+// buf. Search walks the upper levels with findBestEnterPoint and level 0
+// with searchAtLayer, which skips the links of nodes only on level 0.
+func (h *Hnsw) followedFriends(id uint64, level uint64, buf []uint64) []uint64 {
+	if level == 0 {
+		return h.searchFriendsAt(id, level, buf)
+	}
+	return h.friendsAt(id, level, buf)
+}
+
+// Reconnect links every unreachable node from its nearest reachable
+// neighbours and returns the number of nodes it reconnected. The changes
+// are not written to the write-ahead log, call Checkpoint to persist them.
+func (h *Hnsw) Reconnect() int {
+	h.Lock()
+	defer h.Unlock()
+
+	reconnected := make(map[uint64]bool)
+	maxLayer := h.MaxLayer
+	for level := maxLayer; level < maxLayer+1; level-- { // note: level intentionally overflows/wraps here
+		reachable := h.reachablePerLevel()[level]
+		for _, n := range h.nodeList() {
+			if n.Level < level || reachable[n.Id] {
+				continue
+			}
+
+			s := newSearch(context.Background(), n.P, SearchOptions{})
+			ep := &distqueue.Item{Node: h.Enterpoint, D: h.DistFunc(h.Nodes[h.Enterpoint].P, n.P)}
+			ep = h.findBestEnterPoint(s, ep, level, maxLayer)
+			resultSet := &distqueue.DistQueue{ClosestLast: true}
+			h.searchAtLayer(s, resultSet, h.EfConstruction, ep, level)
+			closest := make([]uint64, resultSet.Len())
+			for i := len(closest) - 1; i >= 0; i-- {
+				closest[i] = resultSet.Pop().Node
+			}
+
+			// link from up to M of the closest nodes whose links are followed
+			linked := uint64(0)
+			var friends []uint64
+			for _, friend := range closest {
+				if linked == h.M {
+					break
+				}
+				if friend == n.Id || (level == 0 && h.Nodes[friend].FriendLevelCount() == 0) {
+					continue
+				}
+				h.link(friend, n.Id, level)
+				// the link may have been pruned again right away
+				friends = h.followedFriends(friend, level, friends[:0])
+				for _, f := range friends {
+					if f == n.Id {
+						linked++
+						break
+					}
+				}
+			}
+			if linked == 0 {
+				continue
+			}
+			reconnected[n.Id] = true
+
+			// nodes linked from n may have become reachable as well
+			reachable[n.Id] = true
+			h.bfs(reachable, []uint64{n.Id}, level)
+		}
+	}
+	return len(reconnected)
+}
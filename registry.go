@@ -0,0 +1,318 @@
+package hnsw
+
+import (
+	"container/list"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/bitsetpool"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// extension of the files the namespaces of a Registry are stored in
+const namespaceExt = ".hnsw"
+
+var (
+	// ErrNamespaceNotFound is returned for a namespace the registry doesn't
+	// hold
+	ErrNamespaceNotFound = errors.New("hnsw: namespace not found")
+	// ErrNamespaceExists is returned when creating a namespace under a name
+	// already taken
+	ErrNamespaceExists = errors.New("hnsw: namespace already exists")
+	// ErrNamespaceInUse is returned when dropping a namespace while With
+	// calls on it are running
+	ErrNamespaceInUse = errors.New("hnsw: namespace in use")
+	errNamespaceName  = errors.New("hnsw: invalid namespace name")
+)
+
+// Registry manages many named indexes stored in one directory. Namespaces
+// are loaded on first use and the least recently used ones are written back
+// and dropped from memory when more than maxLoaded are loaded. All indexes
+// of a registry share one bitset pool.
+type Registry struct {
+	sync.Mutex
+	dir       string
+	maxLoaded int
+	bitset    *bitsetpool.BitsetPool
+
+	namespaces map[string]*namespace
+	// lru holds the loaded namespaces, most recently used first
+	lru *list.List
+}
+
+type namespace struct {
+	name string
+	h    *Hnsw
+	elem *list.Element
+	// pins counts the running With and Save calls, pinned namespaces aren't
+	// evicted or dropped
+	pins int
+	// saving is closed once the namespace evicted has been written back,
+	// nil unless it is being written
+	saving chan struct{}
+}
+
+// OpenRegistry opens the registry stored in dir, creating the directory if
+// needed. No index is loaded until it is used. maxLoaded <= 0 keeps every
+// used namespace in memory.
+func OpenRegistry(dir string, maxLoaded int) (*Registry, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{
+		dir:        dir,
+		maxLoaded:  maxLoaded,
+		bitset:     bitsetpool.New(),
+		namespaces: make(map[string]*namespace),
+		lru:        list.New(),
+	}
+	for _, f := range files {
+		if name := strings.TrimSuffix(f.Name(), namespaceExt); !f.IsDir() && name != f.Name() {
+			r.namespaces[name] = &namespace{name: name}
+		}
+	}
+	return r, nil
+}
+
+func (r *Registry) path(name string) string {
+	return filepath.Join(r.dir, name+namespaceExt)
+}
+
+func validNamespace(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Names returns the names of all namespaces, sorted
+func (r *Registry) Names() []string {
+	r.Lock()
+	defer r.Unlock()
+	names := make([]string, 0, len(r.namespaces))
+	for name := range r.namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Create adds a namespace holding a new index with the parameters of New.
+// It is written to disk by the next Save or when it is evicted.
+func (r *Registry) Create(name string, M uint64, efConstruction uint64, first framework.Point) error {
+	return r.create(name, New(M, efConstruction, first))
+}
+
+// CreateEmpty is like Create but the index is created with NewEmpty
+func (r *Registry) CreateEmpty(name string, M uint64, efConstruction uint64, dim uint64, metric Metric) error {
+	h, err := NewEmpty(M, efConstruction, dim, metric)
+	if err != nil {
+		return err
+	}
+	return r.create(name, h)
+}
+
+func (r *Registry) create(name string, h *Hnsw) error {
+	if !validNamespace(name) {
+		return errNamespaceName
+	}
+	r.Lock()
+	if _, ok := r.namespaces[name]; ok {
+		r.Unlock()
+		return ErrNamespaceExists
+	}
+	h.bitset = r.bitset
+	ns := &namespace{name: name, h: h}
+	r.namespaces[name] = ns
+	ns.elem = r.lru.PushFront(ns)
+	victims := r.evict()
+	r.Unlock()
+	return r.writeBack(victims)
+}
+
+// With calls fn with the index of namespace name, loading it if needed. The
+// namespace isn't evicted while fn runs, fn must not keep h afterwards.
+func (r *Registry) With(name string, fn func(h *Hnsw) error) error {
+	r.Lock()
+	ns, victims, err := r.load(name)
+	r.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := r.writeBack(victims); err != nil {
+		r.unpin(ns)
+		return err
+	}
+
+	err = fn(ns.h)
+	if unpinErr := r.unpin(ns); err == nil {
+		err = unpinErr
+	}
+	return err
+}
+
+// load returns the loaded namespace name pinned, together with the
+// namespaces evicted to make room for it. The caller must hold the lock and
+// pass them to writeBack after releasing it.
+func (r *Registry) load(name string) (*namespace, []*namespace, error) {
+	ns, err := r.lookup(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ns.h != nil {
+		r.lru.MoveToFront(ns.elem)
+		ns.pins++
+		return ns, nil, nil
+	}
+
+	h, err := loadSnapshot(r.path(name))
+	if err != nil {
+		return nil, nil, err
+	}
+	h.bitset = r.bitset
+	ns.h = h
+	ns.elem = r.lru.PushFront(ns)
+	// pin before evicting, so the namespace just loaded stays
+	ns.pins++
+	return ns, r.evict(), nil
+}
+
+// lookup returns namespace name once it isn't being written back, the
+// caller must hold the lock, which is released while waiting
+func (r *Registry) lookup(name string) (*namespace, error) {
+	for {
+		ns, ok := r.namespaces[name]
+		if !ok {
+			return nil, ErrNamespaceNotFound
+		}
+		if ns.saving == nil {
+			return ns, nil
+		}
+		saving := ns.saving
+		r.Unlock()
+		<-saving
+		r.Lock()
+	}
+}
+
+// unpin releases a pin taken by load and writes back the namespaces over the
+// limit
+func (r *Registry) unpin(ns *namespace) error {
+	r.Lock()
+	ns.pins--
+	victims := r.evict()
+	r.Unlock()
+	return r.writeBack(victims)
+}
+
+// evict unloads the least recently used namespaces which aren't pinned until
+// at most maxLoaded are loaded. The caller must hold the lock and pass the
+// namespaces returned to writeBack after releasing it.
+func (r *Registry) evict() []*namespace {
+	if r.maxLoaded <= 0 {
+		return nil
+	}
+	var victims []*namespace
+	for e := r.lru.Back(); e != nil && r.lru.Len() > r.maxLoaded; {
+		ns := e.Value.(*namespace)
+		e = e.Prev()
+		if ns.pins > 0 {
+			continue
+		}
+		r.lru.Remove(ns.elem)
+		ns.elem = nil
+		ns.saving = make(chan struct{})
+		victims = append(victims, ns)
+	}
+	return victims
+}
+
+// writeBack writes the namespaces returned by evict to disk and drops them
+// from memory. Those which can't be written stay loaded.
+func (r *Registry) writeBack(victims []*namespace) error {
+	var err error
+	for _, ns := range victims {
+		checkpointErr := ns.h.Checkpoint(r.path(ns.name))
+		r.Lock()
+		if checkpointErr == nil {
+			ns.h = nil
+		} else {
+			ns.elem = r.lru.PushBack(ns)
+			if err == nil {
+				err = checkpointErr
+			}
+		}
+		close(ns.saving)
+		ns.saving = nil
+		r.Unlock()
+	}
+	return err
+}
+
+// Drop deletes namespace name from memory and disk. It fails with
+// ErrNamespaceInUse while With calls on it are running.
+func (r *Registry) Drop(name string) error {
+	r.Lock()
+	defer r.Unlock()
+	ns, err := r.lookup(name)
+	if err != nil {
+		return err
+	}
+	if ns.pins > 0 {
+		return ErrNamespaceInUse
+	}
+	if ns.elem != nil {
+		r.lru.Remove(ns.elem)
+	}
+	delete(r.namespaces, name)
+	if err := os.Remove(r.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Save writes every loaded namespace to disk, together with the namespaces
+// already on disk it holds the whole registry for OpenRegistry
+func (r *Registry) Save() error {
+	r.Lock()
+	var loaded []*namespace
+	var saving []chan struct{}
+	for _, ns := range r.namespaces {
+		if ns.saving != nil {
+			saving = append(saving, ns.saving)
+		} else if ns.h != nil {
+			ns.pins++
+			loaded = append(loaded, ns)
+		}
+	}
+	r.Unlock()
+
+	var err error
+	for _, ns := range loaded {
+		if err == nil {
+			err = ns.h.Checkpoint(r.path(ns.name))
+		}
+	}
+	// namespaces being evicted are written back by the eviction
+	for _, ch := range saving {
+		<-ch
+	}
+
+	r.Lock()
+	for _, ns := range loaded {
+		ns.pins--
+	}
+	victims := r.evict()
+	r.Unlock()
+	if writeErr := r.writeBack(victims); err == nil {
+		err = writeErr
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package hnsw
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/framework"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, vecs := getTestdata(t)
+	var zero framework.Point = make([]float32, dimsize)
+
+	r, err := OpenRegistry(dir, 2)
+	assert.NoError(t, err)
+	names := []string{"a", "b", "c"}
+	for i, name := range names {
+		assert.NoError(t, r.CreateEmpty(name, uint64(8+4*i), 100, dimsize, L2))
+		assert.NoError(t, r.With(name, func(h *Hnsw) error {
+			for _, v := range vecs[100*i : 100*(i+1)] {
+				h.Add(v)
+			}
+			return nil
+		}))
+	}
+	assert.Equal(t, ErrNamespaceExists, r.Create("a", 16, 100, zero))
+	assert.Error(t, r.Create("../a", 16, 100, zero))
+	assert.Equal(t, ErrNamespaceExists, r.CreateEmpty("b", 16, 100, dimsize, L2))
+	assert.Equal(t, ErrNamespaceNotFound, r.With("d", func(h *Hnsw) error { return nil }))
+	assert.Equal(t, names, r.Names())
+
+	// a was evicted and written to disk, using it again loads it
+	assert.Equal(t, 2, r.lru.Len())
+	_, err = os.Stat(filepath.Join(dir, "a"+namespaceExt))
+	assert.NoError(t, err)
+	assert.NoError(t, r.With("a", func(h *Hnsw) error {
+		assert.Equal(t, uint64(8), h.M)
+		assert.Equal(t, 100, len(h.Nodes))
+		assert.True(t, h.bitset == r.bitset)
+		return nil
+	}))
+	assert.Equal(t, 2, r.lru.Len())
+
+	results := make(map[string]uint64)
+	for _, name := range names {
+		name := name
+		assert.NoError(t, r.With(name, func(h *Hnsw) error {
+			results[name] = h.Search(q, 100, 1).Pop().Node
+			return nil
+		}))
+	}
+	assert.NoError(t, r.Save())
+
+	r, err = OpenRegistry(dir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, names, r.Names())
+	for i, name := range names {
+		name := name
+		assert.NoError(t, r.With(name, func(h *Hnsw) error {
+			assert.Equal(t, uint64(8+4*i), h.M)
+			assert.Equal(t, results[name], h.Search(q, 100, 1).Pop().Node)
+			return nil
+		}))
+	}
+	assert.Equal(t, 3, r.lru.Len())
+
+	assert.NoError(t, r.Drop("b"))
+	assert.Equal(t, []string{"a", "c"}, r.Names())
+	_, err = os.Stat(filepath.Join(dir, "b"+namespaceExt))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRegistryNestedWith(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, err := OpenRegistry(dir, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, r.CreateEmpty("a", 8, 100, dimsize, L2))
+	assert.NoError(t, r.CreateEmpty("b", 8, 100, dimsize, L2))
+
+	// a was evicted by creating b, loading it while b is pinned must not
+	// evict it again
+	assert.NoError(t, r.With("b", func(b *Hnsw) error {
+		return r.With("a", func(a *Hnsw) error {
+			assert.NotNil(t, a)
+			assert.NotNil(t, b)
+			assert.Equal(t, 0, len(a.Nodes))
+			return nil
+		})
+	}))
+	assert.Equal(t, 1, r.lru.Len())
+}
+
+func TestRegistryDropInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, err := OpenRegistry(dir, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, r.CreateEmpty("a", 8, 100, dimsize, L2))
+	assert.NoError(t, r.With("a", func(h *Hnsw) error {
+		assert.Equal(t, ErrNamespaceInUse, r.Drop("a"))
+		return nil
+	}))
+	assert.Equal(t, []string{"a"}, r.Names())
+	assert.NoError(t, r.Drop("a"))
+	assert.Equal(t, ErrNamespaceNotFound, r.Drop("a"))
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, vecs := getTestdata(t)
+	r, err := OpenRegistry(dir, 1)
+	assert.NoError(t, err)
+	names := []string{"a", "b", "c", "d"}
+	for _, name := range names {
+		assert.NoError(t, r.CreateEmpty(name, 8, 100, dimsize, L2))
+	}
+
+	// every namespace is evicted and loaded again between the adds, none
+	// of them may get lost
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			for _, v := range vecs[50*i : 50*(i+1)] {
+				assert.NoError(t, r.With(name, func(h *Hnsw) error {
+					h.Add(v)
+					return nil
+				}))
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	assert.NoError(t, r.Save())
+
+	for _, name := range names {
+		assert.NoError(t, r.With(name, func(h *Hnsw) error {
+			assert.Equal(t, 50, len(h.Nodes))
+			return nil
+		}))
+	}
+	assert.Equal(t, 1, r.lru.Len())
+}
@@ -0,0 +1,141 @@
+package hnsw
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	hnswio "github.com/jnmly/go-hnsw/io"
+)
+
+// VectorSource returns the full precision vectors of the nodes, used to
+// re-rank search results, see SearchOptions.Rerank
+type VectorSource interface {
+	Vector(id uint64) ([]float32, error)
+}
+
+// MemoryVectors is a VectorSource holding the vectors in memory
+type MemoryVectors struct {
+	sync.RWMutex
+	vectors map[uint64][]float32
+}
+
+func NewMemoryVectors() *MemoryVectors {
+	return &MemoryVectors{vectors: make(map[uint64][]float32)}
+}
+
+// Set stores the vector of node id
+func (m *MemoryVectors) Set(id uint64, v []float32) {
+	m.Lock()
+	m.vectors[id] = v
+	m.Unlock()
+}
+
+// Delete removes the vector of node id
+func (m *MemoryVectors) Delete(id uint64) {
+	m.Lock()
+	delete(m.vectors, id)
+	m.Unlock()
+}
+
+func (m *MemoryVectors) Vector(id uint64) ([]float32, error) {
+	m.RLock()
+	v, ok := m.vectors[id]
+	m.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("hnsw: no vector for node %d", id)
+	}
+	return v, nil
+}
+
+// FileVectors is a VectorSource reading the vectors from an fvecs file,
+// record i holds the vector of node i. Such a file can be written with
+// io.FvecsWriter while adding the points to an index created with NewEmpty.
+type FileVectors struct {
+	f   *os.File
+	dim int
+}
+
+// OpenFileVectors opens an fvecs file, all its records must have the
+// dimension of the first one
+func OpenFileVectors(filename string) (*FileVectors, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	var buf [4]byte
+	if _, err := f.ReadAt(buf[:], 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	dim, err := hnswio.VecsDim(buf[:])
+	if err == nil && dim == 0 {
+		err = hnswio.ErrFormat
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileVectors{f: f, dim: dim}, nil
+}
+
+func (fv *FileVectors) Vector(id uint64) ([]float32, error) {
+	record := 4 + 4*fv.dim
+	buf := make([]byte, record)
+	if _, err := fv.f.ReadAt(buf, int64(id)*int64(record)); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("hnsw: no vector for node %d", id)
+		}
+		return nil, err
+	}
+	if dim, err := hnswio.VecsDim(buf); err != nil || dim != fv.dim {
+		return nil, fmt.Errorf("hnsw: unexpected dimension in fvecs record %d", id)
+	}
+	v := make([]float32, fv.dim)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4+4*i:]))
+	}
+	return v, nil
+}
+
+func (fv *FileVectors) Close() error {
+	return fv.f.Close()
+}
+
+// rerank replaces the distances in resultSet by the exact distances of the
+// vectors in opts.Rerank
+func (h *Hnsw) rerank(s *search, resultSet *distqueue.DistQueue) error {
+	distance := s.opts.RerankDistance
+	if distance == nil {
+		distance = h.DistFunc
+	}
+	q := s.q
+	if s.opts.RerankQuery != nil {
+		q = s.opts.RerankQuery
+	}
+
+	items := make([]*distqueue.Item, 0, resultSet.Len())
+	for resultSet.Len() > 0 {
+		items = append(items, resultSet.Pop())
+	}
+	exact := make([]float32, len(items))
+	for i, item := range items {
+		v, err := s.opts.Rerank.Vector(item.Node)
+		if err != nil {
+			// keep the approximate results
+			for _, item := range items {
+				resultSet.PushItem(item)
+			}
+			return err
+		}
+		exact[i] = distance(q, v)
+	}
+	for i, item := range items {
+		resultSet.Push(item.Node, exact[i])
+	}
+	return nil
+}
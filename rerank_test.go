@@ -0,0 +1,96 @@
+package hnsw
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	hnswio "github.com/jnmly/go-hnsw/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRerank(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, vecs := getTestdata(t)
+	vecs = vecs[:300]
+
+	// the index holds coarsely quantised vectors, the sources the originals
+	h := newSmallHnsw()
+	memory := NewMemoryVectors()
+	f, err := os.Create(filepath.Join(dir, "vectors.fvecs"))
+	assert.NoError(t, err)
+	w := hnswio.NewFvecsWriter(f)
+	for _, v := range vecs {
+		coarse := make([]float32, len(v))
+		for i, x := range v {
+			coarse[i] = float32(int(x) / 64 * 64)
+		}
+		memory.Set(h.Add(coarse), v)
+		assert.NoError(t, w.WriteVector(v))
+	}
+	assert.NoError(t, f.Close())
+
+	file, err := OpenFileVectors(filepath.Join(dir, "vectors.fvecs"))
+	assert.NoError(t, err)
+	defer file.Close()
+	v, err := file.Vector(42)
+	assert.NoError(t, err)
+	assert.Equal(t, vecs[42], v)
+	_, err = file.Vector(300)
+	assert.Error(t, err)
+
+	// a corrupt header must not make every read allocate gigabytes
+	corrupt := filepath.Join(dir, "corrupt.fvecs")
+	assert.NoError(t, ioutil.WriteFile(corrupt, []byte{0xff, 0xff, 0xff, 0x7f, 0, 0, 0, 0}, 0644))
+	_, err = OpenFileVectors(corrupt)
+	assert.Equal(t, hnswio.ErrFormat, err)
+
+	// candidates of the coarse search, ranked by exact distance
+	candidates := h.Search(q, 100, 100)
+	var exact []float32
+	for candidates.Len() > 0 {
+		v, _ := memory.Vector(candidates.Pop().Node)
+		exact = append(exact, h.DistFunc(q, v))
+	}
+	sort.Slice(exact, func(i, j int) bool { return exact[i] < exact[j] })
+
+	for _, source := range []VectorSource{memory, file} {
+		res, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Rerank: source})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(10), res.Len())
+		var got []float32
+		for res.Len() > 0 {
+			item := res.Pop()
+			v, _ := memory.Vector(item.Node)
+			assert.Equal(t, h.DistFunc(q, v), item.D)
+			got = append([]float32{item.D}, got...)
+		}
+		assert.Equal(t, exact[:10], got)
+	}
+
+	memory.Delete(1)
+	memory.Delete(2)
+	res, err := h.SearchWithOptions(context.Background(), q, 300, 10, SearchOptions{Rerank: memory})
+	assert.Error(t, err)
+	assert.Equal(t, uint64(10), res.Len())
+
+	// the source holds the vectors as added, so by default the query is
+	// compared as passed rather than normalised
+	n, err := NewEmpty(16, 100, uint64(len(q)), Cosine)
+	assert.NoError(t, err)
+	memory = NewMemoryVectors()
+	for _, v := range vecs[:50] {
+		memory.Set(n.Add(v), v)
+	}
+	res, err = n.SearchWithOptions(context.Background(), q, 50, 1, SearchOptions{Rerank: memory})
+	assert.NoError(t, err)
+	item := res.Pop()
+	v, _ = memory.Vector(item.Node)
+	assert.Equal(t, n.DistFunc(q, v), item.D)
+}
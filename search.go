@@ -0,0 +1,282 @@
+package hnsw
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+	"github.com/willf/bitset"
+)
+
+// ErrBudgetExhausted is returned with the partial results of a search that
+// hit one of the limits in its SearchOptions
+var ErrBudgetExhausted = errors.New("hnsw: search budget exhausted")
+
+// SearchOptions limits the work done by a single query, zero means no limit
+type SearchOptions struct {
+	// MaxDistanceComputations caps the number of distance evaluations
+	MaxDistanceComputations uint64
+	// MaxVisited caps the number of nodes whose friends are expanded,
+	// i.e. the number of hops through the graph on all levels
+	MaxVisited uint64
+
+	// Rerank, if not nil, provides the full precision vectors of the ef
+	// candidates, which are scored again with RerankDistance, DistFunc if
+	// nil, before the K best are kept. Sources hold the vectors as they were
+	// passed to Add, so RerankQuery defaults to q as passed to the search,
+	// before the transform and normalisation of the index are applied.
+	Rerank         VectorSource
+	RerankDistance func([]float32, []float32) float32
+	RerankQuery    []float32
+
+	// Diversity, if not 0, re-ranks the ef candidates with maximal marginal
+	// relevance. Results are picked greedily by their distance to the query
+	// weighed by 1 - Diversity against their distance to the results picked
+	// before weighed by Diversity, so 1 ignores the query.
+	Diversity float32
+
+	// Tags, if not nil, restricts the results to the nodes matching the
+	// expression. The graph is still traversed through other nodes. If at
+	// most ef nodes match they are scored directly instead.
+	Tags TagExpr
+
+	// Stats, if not nil, is filled in with the work done by the search
+	Stats *SearchStats
+	// Trace, if not nil, is called for every node the search expands
+	Trace TraceFunc
+}
+
+// SearchStats describes the work done by a single search
+type SearchStats struct {
+	DistanceComputations uint64
+	// VisitedPerLayer holds the number of nodes expanded, indexed by level
+	VisitedPerLayer []uint64
+	// EnterPointHops counts the moves to a closer enterpoint on the upper levels
+	EnterPointHops uint64
+	// CandidateQueueSize is the size of the candidate queue when the search
+	// on level 0 ended
+	CandidateQueueSize uint64
+}
+
+// TraceFunc receives the nodes expanded by a search in traversal order,
+// together with the level they were expanded at and their distance to the
+// query
+type TraceFunc func(level uint64, node uint64, d float32)
+
+// search holds the state of one query while it walks the graph
+type search struct {
+	ctx  context.Context
+	q    framework.Point
+	opts SearchOptions
+	// filter holds the nodes allowed in the results, nil allows all
+	filter *bitset.BitSet
+
+	distances uint64
+	visited   uint64
+	err       error
+
+	visitedPerLayer []uint64
+	hops            uint64
+	candidates      uint64
+}
+
+func newSearch(ctx context.Context, q framework.Point, opts SearchOptions) *search {
+	return &search{ctx: ctx, q: q, opts: opts}
+}
+
+// checkContext stops the search if its context is done
+func (s *search) checkContext() bool {
+	if s.err == nil {
+		s.err = s.ctx.Err()
+	}
+	return s.err == nil
+}
+
+// match reports whether node n may be returned
+func (s *search) match(n uint64) bool {
+	return s.filter == nil || s.filter.Test(uint(n))
+}
+
+// visit accounts for expanding node at level, it returns false once the
+// search has to stop
+func (s *search) visit(level uint64, node uint64, d float32) bool {
+	if s.err != nil {
+		return false
+	}
+	if s.opts.MaxVisited > 0 && s.visited >= s.opts.MaxVisited {
+		s.err = ErrBudgetExhausted
+		return false
+	}
+	s.visited++
+	if s.opts.Stats != nil {
+		for uint64(len(s.visitedPerLayer)) <= level {
+			s.visitedPerLayer = append(s.visitedPerLayer, 0)
+		}
+		s.visitedPerLayer[level]++
+	}
+	if s.opts.Trace != nil {
+		s.opts.Trace(level, node, d)
+	}
+	return true
+}
+
+// report copies the work done so far to opts.Stats
+func (s *search) report() {
+	if s.opts.Stats == nil {
+		return
+	}
+	*s.opts.Stats = SearchStats{
+		DistanceComputations: s.distances,
+		VisitedPerLayer:      s.visitedPerLayer,
+		EnterPointHops:       s.hops,
+		CandidateQueueSize:   s.candidates,
+	}
+}
+
+// distance returns the distance from the query to node n, it returns false
+// once the search has to stop
+func (s *search) distance(h *Hnsw, n uint64) (float32, bool) {
+	if s.err != nil {
+		return 0, false
+	}
+	if s.opts.MaxDistanceComputations > 0 && s.distances >= s.opts.MaxDistanceComputations {
+		s.err = ErrBudgetExhausted
+		return 0, false
+	}
+	s.distances++
+	return h.DistFunc(s.q, h.node(n).P), true
+}
+
+// SearchWithOptions is like SearchContext but also stops when the budget in
+// opts is used up. In that case the K best nodes found so far are returned
+// together with ErrBudgetExhausted.
+func (h *Hnsw) SearchWithOptions(ctx context.Context, q framework.Point, ef uint64, K uint64, opts SearchOptions) (*distqueue.DistQueue, error) {
+	start := time.Now()
+	resultSet := &distqueue.DistQueue{Size: ef + 1, ClosestLast: true}
+	// the raw query, comparable to the vectors of the source
+	if opts.Rerank != nil && opts.RerankQuery == nil {
+		opts.RerankQuery = q
+	}
+	q, err := h.preprocess(q)
+	if err != nil {
+		return resultSet, err
+	}
+	s := newSearch(ctx, q, opts)
+
+	h.rlock()
+	if h.empty() {
+		h.RUnlock()
+		return resultSet, ErrEmptyIndex
+	}
+	if opts.Tags != nil {
+		s.filter = h.tags.eval(opts.Tags, atomic.LoadUint64(&h.Sequence))
+	}
+	if s.filter != nil && s.filter.Count() <= uint(ef) {
+		// walking the graph would visit most of it to find so few nodes
+		err = h.searchMatching(s, resultSet, ef)
+	} else {
+		currentMaxLayer := atomic.LoadUint64(&h.MaxLayer)
+		enterpoint := atomic.LoadUint64(&h.Enterpoint)
+		d, _ := s.distance(h, enterpoint)
+		ep := &distqueue.Item{Node: enterpoint, D: d}
+
+		// first pass, find best ep
+		ep = h.findBestEnterPoint(s, ep, 0, currentMaxLayer)
+
+		err = h.searchAtLayer(s, resultSet, ef, ep, 0)
+	}
+	metrics := h.metrics
+	h.RUnlock()
+
+	// the vector source may do I/O, don't block writers meanwhile
+	if opts.Rerank != nil && err == nil {
+		err = h.rerank(s, resultSet)
+	}
+	if opts.Diversity != 0 {
+		h.diversify(resultSet, K, opts.Diversity)
+	}
+	s.report()
+
+	for resultSet.Len() > K {
+		resultSet.Pop()
+	}
+	metrics.ObserveSearch(time.Since(start))
+	return resultSet, err
+}
+
+// searchMatching collects the ef nodes in s.filter closest to the query in
+// resultSet by scoring all of them
+func (h *Hnsw) searchMatching(s *search, resultSet *distqueue.DistQueue, ef uint64) error {
+	i := 0
+	for n, ok := s.filter.NextSet(0); ok; n, ok = s.filter.NextSet(n + 1) {
+		if i%ctxCheckInterval == 0 && !s.checkContext() {
+			break
+		}
+		i++
+		if h.node(uint64(n)) == nil {
+			continue
+		}
+		d, more := s.distance(h, uint64(n))
+		if !more {
+			break
+		}
+		resultSet.Push(uint64(n), d)
+		if resultSet.Len() > ef {
+			resultSet.Pop()
+		}
+	}
+	return s.err
+}
+
+// diversify replaces the candidates in resultSet by the K picked with
+// maximal marginal relevance, see SearchOptions.Diversity. Like
+// getNeighborsByHeuristic it prefers candidates far from the ones already
+// kept, but trades that off against the distance to the query.
+// Candidates removed from the index in the meantime are dropped.
+func (h *Hnsw) diversify(resultSet *distqueue.DistQueue, K uint64, diversity float32) {
+	candidates := make([]*distqueue.Item, 0, resultSet.Len())
+	points := make([]framework.Point, 0, resultSet.Len())
+	for resultSet.Len() > 0 {
+		c := resultSet.Pop()
+		if n := h.node(c.Node); n != nil {
+			candidates = append(candidates, c)
+			points = append(points, n.P)
+		}
+	}
+	// closest to any picked candidate so far
+	closest := make([]float32, len(candidates))
+	for i := range closest {
+		closest[i] = float32(math.Inf(1))
+	}
+
+	for picked := uint64(0); picked < K && len(candidates) > 0; picked++ {
+		best := 0
+		var bestScore float32
+		for i, c := range candidates {
+			// the first pick is the closest candidate
+			score := -c.D
+			if picked > 0 {
+				score = -(1-diversity)*c.D + diversity*closest[i]
+			}
+			if i == 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		resultSet.PushItem(candidates[best])
+		pp := points[best]
+		last := len(candidates) - 1
+		candidates[best], closest[best], points[best] = candidates[last], closest[last], points[last]
+		candidates, closest, points = candidates[:last], closest[:last], points[:last]
+
+		for i, p := range points {
+			if d := h.DistFunc(pp, p); d < closest[i] {
+				closest[i] = d
+			}
+		}
+	}
+}
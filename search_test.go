@@ -0,0 +1,51 @@
+package hnsw
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchDiversity(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+		// near duplicates of every point
+		d := append([]float32(nil), v...)
+		d[0] += 0.001
+		h.Add(d)
+	}
+
+	minPairwise := func(res *distqueue.DistQueue) (float32, []uint64) {
+		var ids []uint64
+		for res.Len() > 0 {
+			ids = append(ids, res.Pop().Node)
+		}
+		min := float32(math.Inf(1))
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				if d := h.DistFunc(h.Nodes[ids[i]].P, h.Nodes[ids[j]].P); d < min {
+					min = d
+				}
+			}
+		}
+		return min, ids
+	}
+
+	plain, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{})
+	assert.NoError(t, err)
+	plainMin, plainIDs := minPairwise(plain)
+
+	diverse, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Diversity: 0.5})
+	assert.NoError(t, err)
+	diverseMin, diverseIDs := minPairwise(diverse)
+
+	assert.Len(t, diverseIDs, 10)
+	assert.True(t, diverseMin > plainMin, "diverse %v plain %v", diverseMin, plainMin)
+	// the closest result is always kept, it is popped last
+	assert.Contains(t, diverseIDs, plainIDs[len(plainIDs)-1])
+}
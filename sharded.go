@@ -0,0 +1,320 @@
+package hnsw
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// Router picks the shard for a point, it must return a value in [0, n)
+type Router func(q framework.Point, n int) int
+
+var errNoShards = errors.New("hnsw: sharded index without shards")
+
+// HashRouter spreads points over the shards by hashing their coordinates.
+// It returns 0 for n <= 0.
+func HashRouter(q framework.Point, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	var buf [4]byte
+	for _, f := range q {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f))
+		hash.Write(buf[:])
+	}
+	return int(hash.Sum64() % uint64(n))
+}
+
+// ShardedIndex partitions points over several indexes. Adds go to the shard
+// picked by the router, searches query all shards concurrently and merge
+// their results. The ids of a sharded index encode the shard, shard i holds
+// the ids with id % n == i.
+type ShardedIndex struct {
+	shards []*Hnsw
+	route  Router
+}
+
+// NewSharded creates n > 0 shards with the parameters of New. A nil route
+// uses HashRouter.
+func NewSharded(n int, M uint64, efConstruction uint64, first framework.Point, route Router) (*ShardedIndex, error) {
+	if n <= 0 {
+		return nil, errNoShards
+	}
+	shards := make([]*Hnsw, n)
+	for i := range shards {
+		shards[i] = New(M, efConstruction, first)
+	}
+	return newSharded(shards, route), nil
+}
+
+// NewShardedEmpty creates n > 0 empty shards with the parameters of
+// NewEmpty. A nil route uses HashRouter.
+func NewShardedEmpty(n int, M uint64, efConstruction uint64, dim uint64, metric Metric, route Router) (*ShardedIndex, error) {
+	if n <= 0 {
+		return nil, errNoShards
+	}
+	shards := make([]*Hnsw, n)
+	for i := range shards {
+		h, err := NewEmpty(M, efConstruction, dim, metric)
+		if err != nil {
+			return nil, err
+		}
+		shards[i] = h
+	}
+	return newSharded(shards, route), nil
+}
+
+func newSharded(shards []*Hnsw, route Router) *ShardedIndex {
+	if route == nil {
+		route = HashRouter
+	}
+	return &ShardedIndex{shards: shards, route: route}
+}
+
+// Shards returns the underlying indexes, their node ids are local to them
+func (s *ShardedIndex) Shards() []*Hnsw {
+	return s.shards
+}
+
+// globalID converts the id of a node in shard i to an id of the sharded index
+func (s *ShardedIndex) globalID(i int, id uint64) uint64 {
+	return id*uint64(len(s.shards)) + uint64(i)
+}
+
+// localID returns the shard of id and the id of the node within that shard
+func (s *ShardedIndex) localID(id uint64) (int, uint64) {
+	n := uint64(len(s.shards))
+	return int(id % n), id / n
+}
+
+func (s *ShardedIndex) Add(q framework.Point) uint64 {
+	id, err := s.AddContext(context.Background(), q)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// AddContext adds q to the shard picked by the router, see Hnsw.AddContext
+func (s *ShardedIndex) AddContext(ctx context.Context, q framework.Point) (uint64, error) {
+	i := s.route(q, len(s.shards))
+	if i < 0 || i >= len(s.shards) {
+		return 0, fmt.Errorf("hnsw: router picked shard %d of %d", i, len(s.shards))
+	}
+	id, err := s.shards[i].AddContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return s.globalID(i, id), nil
+}
+
+// BulkLoad adds every vector returned by r until io.EOF and returns the
+// assigned ids in input order
+func (s *ShardedIndex) BulkLoad(r VectorReader) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	for {
+		v, err := r.ReadVector()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return ids, err
+		}
+		id, err := s.AddContext(context.Background(), v)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+}
+
+// TryAdd is like Add but returns an error instead of panicking on invalid
+// points
+func (s *ShardedIndex) TryAdd(q framework.Point) (uint64, error) {
+	return s.AddContext(context.Background(), q)
+}
+
+// Remove deletes node id, see Hnsw.Remove
+func (s *ShardedIndex) Remove(id uint64) {
+	if err := s.TryRemove(id); err != nil && err != ErrNotFound {
+		panic(err)
+	}
+}
+
+// TryRemove deletes node id from its shard, see Hnsw.TryRemove
+func (s *ShardedIndex) TryRemove(id uint64) error {
+	i, local := s.localID(id)
+	return s.shards[i].TryRemove(local)
+}
+
+func (s *ShardedIndex) Search(q framework.Point, ef uint64, K uint64) *distqueue.DistQueue {
+	resultSet, _ := s.SearchContext(context.Background(), q, ef, K)
+	return resultSet
+}
+
+// TrySearch is like Search but returns the error for invalid queries and
+// empty indexes
+func (s *ShardedIndex) TrySearch(q framework.Point, ef uint64, K uint64) (*distqueue.DistQueue, error) {
+	return s.SearchContext(context.Background(), q, ef, K)
+}
+
+func (s *ShardedIndex) SearchContext(ctx context.Context, q framework.Point, ef uint64, K uint64) (*distqueue.DistQueue, error) {
+	return s.SearchWithOptions(ctx, q, ef, K, SearchOptions{})
+}
+
+// SearchWithOptions searches every shard with opts and merges the K
+// closest results. The budget applies to each shard on its own, the stats
+// are summed up over all shards, the trace receives global ids and so does
+// the Rerank source. The
+// first error of any shard is returned with the merged results, empty
+// shards are skipped unless all of them are empty.
+func (s *ShardedIndex) SearchWithOptions(ctx context.Context, q framework.Point, ef uint64, K uint64, opts SearchOptions) (*distqueue.DistQueue, error) {
+	results := make([]*distqueue.DistQueue, len(s.shards))
+	errs := make([]error, len(s.shards))
+	stats := make([]SearchStats, len(s.shards))
+	var traceLock sync.Mutex
+
+	wg := &sync.WaitGroup{}
+	for i := range s.shards {
+		shardOpts := opts
+		if opts.Stats != nil {
+			shardOpts.Stats = &stats[i]
+		}
+		if opts.Rerank != nil {
+			shardOpts.Rerank = shardVectors{source: opts.Rerank, s: s, shard: i}
+		}
+		if opts.Trace != nil {
+			i := i
+			shardOpts.Trace = func(level uint64, node uint64, d float32) {
+				traceLock.Lock()
+				opts.Trace(level, s.globalID(i, node), d)
+				traceLock.Unlock()
+			}
+		}
+		wg.Add(1)
+		go func(i int, shardOpts SearchOptions) {
+			results[i], errs[i] = s.shards[i].SearchWithOptions(ctx, q, ef, K, shardOpts)
+			wg.Done()
+		}(i, shardOpts)
+	}
+	wg.Wait()
+
+	resultSet := &distqueue.DistQueue{Size: K + 1, ClosestLast: true}
+	for i, r := range results {
+		for r.Len() > 0 {
+			item := r.Pop()
+			resultSet.Push(s.globalID(i, item.Node), item.D)
+			if resultSet.Len() > K {
+				resultSet.Pop()
+			}
+		}
+	}
+
+	if opts.Stats != nil {
+		*opts.Stats = SearchStats{}
+		for _, st := range stats {
+			opts.Stats.DistanceComputations += st.DistanceComputations
+			opts.Stats.EnterPointHops += st.EnterPointHops
+			opts.Stats.CandidateQueueSize += st.CandidateQueueSize
+			for level, n := range st.VisitedPerLayer {
+				for len(opts.Stats.VisitedPerLayer) <= level {
+					opts.Stats.VisitedPerLayer = append(opts.Stats.VisitedPerLayer, 0)
+				}
+				opts.Stats.VisitedPerLayer[level] += n
+			}
+		}
+	}
+
+	empty := 0
+	for _, err := range errs {
+		if err == ErrEmptyIndex {
+			empty++
+		} else if err != nil {
+			return resultSet, err
+		}
+	}
+	if empty == len(s.shards) {
+		return resultSet, ErrEmptyIndex
+	}
+	return resultSet, nil
+}
+
+// shardVectors translates the node ids of a shard to global ids before
+// looking up their vectors
+type shardVectors struct {
+	source VectorSource
+	s      *ShardedIndex
+	shard  int
+}
+
+func (v shardVectors) Vector(id uint64) ([]float32, error) {
+	return v.source.Vector(v.s.globalID(v.shard, id))
+}
+
+// Stats returns the statistics of every shard
+func (s *ShardedIndex) Stats() string {
+	buf := &bytes.Buffer{}
+	for i, h := range s.shards {
+		fmt.Fprintf(buf, "Shard %d\n%s", i, h.Stats())
+	}
+	return buf.String()
+}
+
+// Save writes every shard to w, prefixed by the number of shards and the
+// length of each shard
+func (s *ShardedIndex) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	if _, err := bw.Write(buf[:binary.PutUvarint(buf, uint64(len(s.shards)))]); err != nil {
+		return err
+	}
+	shard := &bytes.Buffer{}
+	for _, h := range s.shards {
+		shard.Reset()
+		if err := h.Save(shard); err != nil {
+			return err
+		}
+		if _, err := bw.Write(buf[:binary.PutUvarint(buf, uint64(shard.Len()))]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(shard.Bytes()); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadSharded reads an index previously written by ShardedIndex.Save. The
+// router isn't saved, route has to be the one used before.
+func LoadSharded(r io.Reader, route Router) (*ShardedIndex, error) {
+	br := bufio.NewReader(r)
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errNoShards
+	}
+	shards := make([]*Hnsw, n)
+	for i := range shards {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if shards[i], err = Load(io.LimitReader(br, int64(size))); err != nil {
+			return nil, err
+		}
+	}
+	return newSharded(shards, route), nil
+}
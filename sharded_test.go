@@ -0,0 +1,139 @@
+package hnsw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/framework"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedIndex(t *testing.T) {
+	q, vecs := getTestdata(t)
+	vecs = vecs[:400]
+	s, err := NewShardedEmpty(4, 16, 100, dimsize, L2, nil)
+	assert.NoError(t, err)
+
+	ids := make([]uint64, len(vecs))
+	for i, v := range vecs {
+		ids[i] = s.Add(v)
+	}
+	for _, h := range s.Shards() {
+		assert.True(t, len(h.Nodes) > 0)
+	}
+
+	// every point can be found under its global id
+	found := 0
+	for i, v := range vecs {
+		if s.Search(v, 50, 1).Pop().Node == ids[i] {
+			found++
+		}
+	}
+	assert.True(t, found > 390, "found %d", found)
+
+	// merged results are the K closest of all shards
+	var stats SearchStats
+	traced := 0
+	res, err := s.SearchWithOptions(context.Background(), q, 50, 10, SearchOptions{
+		Stats: &stats,
+		Trace: func(level uint64, node uint64, d float32) { traced++ },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Len())
+	visited := uint64(0)
+	for _, n := range stats.VisitedPerLayer {
+		visited += n
+	}
+	assert.Equal(t, uint64(traced), visited)
+	last := float32(-1)
+	for res.Len() > 0 {
+		item := res.Pop()
+		if last >= 0 {
+			assert.True(t, item.D <= last)
+		}
+		last = item.D
+	}
+
+	i, local := s.localID(ids[7])
+	s.Remove(ids[7])
+	assert.Nil(t, s.Shards()[i].Nodes[local])
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, s.Save(buf))
+	loaded, err := LoadSharded(buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, len(s.Shards()), len(loaded.Shards()))
+	for i := range s.Shards() {
+		assert.Equal(t, FullState(s.Shards()[i]), FullState(loaded.Shards()[i]))
+	}
+	assert.Equal(t, ids[8], loaded.Search(vecs[8], 50, 1).Pop().Node)
+}
+
+func TestShardedErrors(t *testing.T) {
+	_, vecs := getTestdata(t)
+	// everything goes to shard 0, shard 1 stays empty
+	s, err := NewShardedEmpty(2, 16, 100, dimsize, L2, func(framework.Point, int) int { return 0 })
+	assert.NoError(t, err)
+	for _, v := range vecs[:50] {
+		_, err := s.TryAdd(v)
+		assert.NoError(t, err)
+	}
+	_, err = s.TryAdd(vecs[0][:3])
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	_, err = s.TrySearch(vecs[0][:3], 50, 1)
+	assert.True(t, errors.Is(err, ErrDimensionMismatch))
+	assert.Equal(t, ErrNotFound, s.TryRemove(s.globalID(1, 0)))
+
+	// an empty shard doesn't fail the search of the others
+	res, err := s.TrySearch(vecs[0], 50, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), res.Len())
+
+	for _, h := range s.Shards() {
+		for id := range h.Nodes {
+			assert.NoError(t, h.TryRemove(id))
+		}
+	}
+	_, err = s.TrySearch(vecs[0], 50, 5)
+	assert.Equal(t, ErrEmptyIndex, err)
+
+	_, err = NewShardedEmpty(0, 16, 100, dimsize, L2, nil)
+	assert.Error(t, err)
+	_, err = NewSharded(-1, 16, 100, vecs[0], nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, HashRouter(vecs[0], 0))
+
+	s, err = NewShardedEmpty(2, 16, 100, dimsize, L2, func(framework.Point, int) int { return 2 })
+	assert.NoError(t, err)
+	_, err = s.TryAdd(vecs[0])
+	assert.Error(t, err)
+	assert.Panics(t, func() { s.Add(vecs[0]) })
+}
+
+func TestShardedRerank(t *testing.T) {
+	q, vecs := getTestdata(t)
+	s, err := NewShardedEmpty(3, 16, 100, dimsize, L2, nil)
+	assert.NoError(t, err)
+
+	// the shards hold coarse vectors, the source the originals by global id
+	memory := NewMemoryVectors()
+	for _, v := range vecs[:300] {
+		coarse := make([]float32, len(v))
+		for i, x := range v {
+			coarse[i] = float32(int(x) / 64 * 64)
+		}
+		memory.Set(s.Add(coarse), v)
+	}
+
+	res, err := s.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Rerank: memory})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), res.Len())
+	for res.Len() > 0 {
+		item := res.Pop()
+		v, err := memory.Vector(item.Node)
+		assert.NoError(t, err)
+		assert.Equal(t, s.Shards()[0].DistFunc(q, v), item.D)
+	}
+}
@@ -0,0 +1,181 @@
+package hnsw
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+// Snapshot is a consistent point-in-time view of the index. While a snapshot
+// is open, writers keep a serialised copy of every node before they modify
+// or remove it, so the snapshot can be written out while inserts continue
+// against the live index.
+type Snapshot struct {
+	sync.Mutex
+	h         *Hnsw
+	header    framework.Hnsw
+	ids       []uint64
+	preserved map[uint64][]byte
+}
+
+// Snapshot opens a point-in-time view of the index, it must be closed
+// when it's no longer needed
+func (h *Hnsw) Snapshot() *Snapshot {
+	h.Lock()
+	s := h.snapshot()
+	h.Unlock()
+	s.sortIDs()
+	return s
+}
+
+// snapshot registers a new snapshot, the caller must hold the write lock
+func (h *Hnsw) snapshot() *Snapshot {
+	s := &Snapshot{
+		h:         h,
+		header:    h.Hnsw,
+		ids:       make([]uint64, 0, len(h.Nodes)),
+		preserved: make(map[uint64][]byte),
+	}
+	s.header.Nodes = nil
+	s.header.CountLevel = make(map[uint64]uint64, len(h.CountLevel))
+	for k, v := range h.CountLevel {
+		s.header.CountLevel[k] = v
+	}
+	for id := range h.Nodes {
+		s.ids = append(s.ids, id)
+	}
+
+	if h.snapshots == nil {
+		h.snapshots = make(map[*Snapshot]bool)
+	}
+	h.snapshots[s] = true
+	return s
+}
+
+func (s *Snapshot) sortIDs() {
+	sort.Slice(s.ids, func(i, j int) bool { return s.ids[i] < s.ids[j] })
+}
+
+// Close releases the snapshot, writers stop preserving nodes for it
+func (s *Snapshot) Close() {
+	s.h.Lock()
+	delete(s.h.snapshots, s)
+	s.h.Unlock()
+
+	s.Lock()
+	s.preserved = nil
+	s.Unlock()
+}
+
+// preserve saves the current state of node n for every open snapshot that
+// hasn't seen a modification of it yet. The caller must hold the lock of the
+// node or the write lock of the index.
+func (h *Hnsw) preserve(id uint64, n *framework.Node) {
+	if len(h.snapshots) == 0 || n == nil {
+		return
+	}
+	var data []byte
+	for s := range h.snapshots {
+		// nodes added after the snapshot was taken are not part of it
+		if id >= s.header.Sequence {
+			continue
+		}
+		s.Lock()
+		if _, ok := s.preserved[id]; !ok {
+			if data == nil {
+				data, _ = n.Marshal()
+			}
+			s.preserved[id] = data
+		}
+		s.Unlock()
+	}
+}
+
+// mutable returns node id after preserving it for open snapshots, use it
+// for every node that is about to be modified while holding the write lock
+func (h *Hnsw) mutable(id uint64) *framework.Node {
+	n := h.Nodes[id]
+	h.preserve(id, n)
+	return n
+}
+
+// node returns the serialised node as it was when the snapshot was taken
+func (s *Snapshot) node(id uint64) ([]byte, error) {
+	s.h.RLock()
+	defer s.h.RUnlock()
+
+	n := s.h.node(id)
+	l := s.h.nodeLock(id)
+	l.RLock()
+	defer l.RUnlock()
+	s.Lock()
+	defer s.Unlock()
+
+	if data, ok := s.preserved[id]; ok {
+		return data, nil
+	}
+	if n == nil {
+		return nil, nil
+	}
+	return n.Marshal()
+}
+
+// WriteTo streams the snapshot to w in the same format as framework.Hnsw.Marshal
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	data, err := s.header.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	written, err := w.Write(data)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+
+	// every node is a map entry of field 10: key 1 is the id, value 2 the node
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64)
+	for _, id := range s.ids {
+		data, err := s.node(id)
+		if err != nil {
+			return total, err
+		}
+		if data == nil {
+			continue
+		}
+
+		buf = buf[:0]
+		entry := 1 + uvarintSize(id) + 1 + uvarintSize(uint64(len(data))) + len(data)
+		buf = append(buf, 0x52)
+		buf = appendUvarint(buf, uint64(entry))
+		buf = append(buf, 0x8)
+		buf = appendUvarint(buf, id)
+		buf = append(buf, 0x12)
+		buf = appendUvarint(buf, uint64(len(data)))
+
+		written, err = w.Write(buf)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+		written, err = w.Write(data)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func uvarintSize(v uint64) int {
+	var tmp [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(tmp[:], v)
+}
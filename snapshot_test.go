@@ -0,0 +1,63 @@
+package hnsw
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotMatchesMarshal(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:200] {
+		h.Add(v)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.Save(buf))
+
+	g := &Hnsw{}
+	assert.NoError(t, g.Unmarshal(buf.Bytes()))
+	assert.Equal(t, FullState(h), FullState(g))
+}
+
+func TestSnapshotConcurrentWriters(t *testing.T) {
+	h := newSmallHnsw()
+	_, vecs := getTestdata(t)
+	for _, v := range vecs[:300] {
+		h.Add(v)
+	}
+
+	expected := FullState(h)
+	s := h.Snapshot()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		for i, v := range vecs[300:600] {
+			h.Add(v)
+			if i%10 == 0 {
+				h.Remove(uint64(i + 1))
+			}
+		}
+		wg.Done()
+	}()
+
+	buf := &bytes.Buffer{}
+	_, err := s.WriteTo(buf)
+	assert.NoError(t, err)
+	wg.Wait()
+	s.Close()
+	assert.Equal(t, 0, len(h.snapshots))
+
+	g, err := Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, FullState(g))
+	assert.NotEqual(t, expected, FullState(h))
+
+	// the loaded index is usable
+	g.Add(vecs[700])
+	Search(g, vecs[0])
+}
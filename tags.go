@@ -0,0 +1,174 @@
+package hnsw
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/framework"
+	"github.com/willf/bitset"
+)
+
+// tagIndex keeps a bitmap of node ids per tag
+type tagIndex struct {
+	sync.RWMutex
+	postings map[uint64]*bitset.BitSet
+}
+
+func (t *tagIndex) add(id uint64, tags []uint64) {
+	if len(tags) == 0 {
+		return
+	}
+	t.Lock()
+	if t.postings == nil {
+		t.postings = make(map[uint64]*bitset.BitSet)
+	}
+	for _, tag := range tags {
+		b, ok := t.postings[tag]
+		if !ok {
+			b = bitset.New(uint(id) + 1)
+			t.postings[tag] = b
+		}
+		b.Set(uint(id))
+	}
+	t.Unlock()
+}
+
+func (t *tagIndex) remove(id uint64, tags []uint64) {
+	if len(tags) == 0 {
+		return
+	}
+	t.Lock()
+	for _, tag := range tags {
+		if b, ok := t.postings[tag]; ok {
+			b.Clear(uint(id))
+			if b.None() {
+				delete(t.postings, tag)
+			}
+		}
+	}
+	t.Unlock()
+}
+
+// eval returns the ids matching e, universe bounds the ids of Not
+func (t *tagIndex) eval(e TagExpr, universe uint64) *bitset.BitSet {
+	t.RLock()
+	defer t.RUnlock()
+	return e.eval(t, uint(universe))
+}
+
+// TagExpr is a boolean expression over the tags of a node, build it with
+// Tag, And, Or and Not
+type TagExpr interface {
+	// eval returns a new bitmap of the matching node ids
+	eval(t *tagIndex, universe uint) *bitset.BitSet
+}
+
+type tagExpr uint64
+
+func (e tagExpr) eval(t *tagIndex, universe uint) *bitset.BitSet {
+	if b, ok := t.postings[uint64(e)]; ok {
+		return b.Clone()
+	}
+	return bitset.New(0)
+}
+
+type andExpr []TagExpr
+
+func (e andExpr) eval(t *tagIndex, universe uint) *bitset.BitSet {
+	if len(e) == 0 {
+		return bitset.New(universe).Complement()
+	}
+	b := e[0].eval(t, universe)
+	for _, o := range e[1:] {
+		b.InPlaceIntersection(o.eval(t, universe))
+	}
+	return b
+}
+
+type orExpr []TagExpr
+
+func (e orExpr) eval(t *tagIndex, universe uint) *bitset.BitSet {
+	b := bitset.New(0)
+	for _, o := range e {
+		b.InPlaceUnion(o.eval(t, universe))
+	}
+	return b
+}
+
+type notExpr struct{ e TagExpr }
+
+func (e notExpr) eval(t *tagIndex, universe uint) *bitset.BitSet {
+	b := bitset.New(universe).Complement()
+	b.InPlaceDifference(e.e.eval(t, universe))
+	return b
+}
+
+// Tag matches the nodes carrying tag
+func Tag(tag uint64) TagExpr {
+	return tagExpr(tag)
+}
+
+// And matches the nodes matching all of exprs, no exprs match every node
+func And(exprs ...TagExpr) TagExpr {
+	return andExpr(exprs)
+}
+
+// Or matches the nodes matching any of exprs
+func Or(exprs ...TagExpr) TagExpr {
+	return orExpr(exprs)
+}
+
+// Not matches the nodes not matching e
+func Not(e TagExpr) TagExpr {
+	return notExpr{e}
+}
+
+// AddWithTags is like Add and attaches tags to the new node
+func (h *Hnsw) AddWithTags(q framework.Point, tags []uint64) uint64 {
+	id, err := h.AddWithTagsContext(context.Background(), q, tags)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// AddWithTagsContext is like AddContext and attaches tags to the new node
+func (h *Hnsw) AddWithTagsContext(ctx context.Context, q framework.Point, tags []uint64) (uint64, error) {
+	return h.addContext(ctx, q, tags, 0)
+}
+
+// Tags returns the tags of node id
+func (h *Hnsw) Tags(id uint64) []uint64 {
+	h.RLock()
+	defer h.RUnlock()
+	if n := h.node(id); n != nil {
+		return append([]uint64(nil), n.Tags...)
+	}
+	return nil
+}
+
+// SetTags replaces the tags of node id
+func (h *Hnsw) SetTags(id uint64, tags []uint64) error {
+	h.lock()
+	defer h.Unlock()
+
+	if _, ok := h.Nodes[id]; !ok {
+		return ErrNotFound
+	}
+	if h.wal != nil {
+		if err := h.wal.logTags(id, tags); err != nil {
+			return err
+		}
+	}
+	h.setTags(id, tags)
+	return nil
+}
+
+// setTags replaces the tags of an existing node, the caller must hold the
+// write lock
+func (h *Hnsw) setTags(id uint64, tags []uint64) {
+	n := h.mutable(id)
+	h.tags.remove(id, n.Tags)
+	n.Tags = append([]uint64(nil), tags...)
+	h.tags.add(id, n.Tags)
+}
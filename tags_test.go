@@ -0,0 +1,114 @@
+package hnsw
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/distqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagFilter(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	for i, v := range vecs[:300] {
+		// tag 1 on every third node, tag 2 on every fifth node
+		var tags []uint64
+		if i%3 == 0 {
+			tags = append(tags, 1)
+		}
+		if i%5 == 0 {
+			tags = append(tags, 2)
+		}
+		h.AddWithTags(v, tags)
+	}
+	hasTag := func(id uint64, tag uint64) bool {
+		for _, t := range h.Tags(id) {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+	search := func(expr TagExpr) []uint64 {
+		res, err := h.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Tags: expr})
+		assert.NoError(t, err)
+		var ids []uint64
+		for res.Len() > 0 {
+			ids = append(ids, res.Pop().Node)
+		}
+		return ids
+	}
+
+	ids := search(And(Tag(1), Tag(2)))
+	assert.Len(t, ids, 10)
+	for _, id := range ids {
+		assert.True(t, hasTag(id, 1) && hasTag(id, 2))
+	}
+	ids = search(Or(Tag(1), Tag(2)))
+	assert.Len(t, ids, 10)
+	for _, id := range ids {
+		assert.True(t, hasTag(id, 1) || hasTag(id, 2))
+	}
+	ids = search(And(Tag(1), Not(Tag(2))))
+	assert.Len(t, ids, 10)
+	for _, id := range ids {
+		assert.True(t, hasTag(id, 1) && !hasTag(id, 2))
+	}
+	assert.Empty(t, search(Tag(3)))
+
+	// retagging and removing update the postings
+	h.SetTags(ids[0], []uint64{3})
+	assert.Equal(t, []uint64{ids[0]}, search(Tag(3)))
+	h.Remove(ids[0])
+	assert.Empty(t, search(Tag(3)))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, h.Save(buf))
+	g, err := Load(buf)
+	assert.NoError(t, err)
+	for _, expr := range []TagExpr{Tag(1), And(Tag(1), Tag(2)), Not(Tag(1))} {
+		res, _ := g.SearchWithOptions(context.Background(), q, 100, 10, SearchOptions{Tags: expr})
+		var loaded []uint64
+		for res.Len() > 0 {
+			loaded = append(loaded, res.Pop().Node)
+		}
+		assert.Equal(t, search(expr), loaded)
+	}
+}
+
+func TestTagFilterRare(t *testing.T) {
+	h := newSmallHnsw()
+	q, vecs := getTestdata(t)
+	rare := make(map[uint64]bool)
+	for i, v := range vecs {
+		var tags []uint64
+		if i%200 == 0 {
+			tags = []uint64{7}
+		}
+		id := h.AddWithTags(v, tags)
+		if tags != nil {
+			rare[id] = true
+		}
+	}
+
+	// the few matching nodes are scored directly, not found by walking the
+	// whole graph
+	var stats SearchStats
+	res, err := h.SearchWithOptions(context.Background(), q, 100, 3, SearchOptions{Tags: Tag(7), Stats: &stats})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(rare)), stats.DistanceComputations)
+
+	brute := &distqueue.DistQueue{ClosestLast: true}
+	for id := range rare {
+		brute.Push(id, h.DistFunc(q, h.Nodes[id].P))
+	}
+	for brute.Len() > 3 {
+		brute.Pop()
+	}
+	assert.Equal(t, uint64(3), res.Len())
+	for res.Len() > 0 {
+		assert.Equal(t, brute.Pop().Node, res.Pop().Node)
+	}
+}
@@ -0,0 +1,200 @@
+package hnsw
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+const (
+	// TransformPCA projects onto the principal components of a sample
+	TransformPCA = 1
+	// TransformRandomProjection projects onto random orthonormal directions
+	TransformRandomProjection = 2
+)
+
+// number of subspace iterations used to find the principal components
+const pcaIterations = 30
+
+// TrainPCA learns a transform onto the dim principal components of sample
+func TrainPCA(sample [][]float32, dim int) (*framework.Transform, error) {
+	inputDim, err := checkSample(sample, dim)
+	if err != nil {
+		return nil, err
+	}
+
+	mean := make([]float64, inputDim)
+	for _, v := range sample {
+		for i, x := range v {
+			mean[i] += float64(x)
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(sample))
+	}
+	centered := make([][]float64, len(sample))
+	for j, v := range sample {
+		centered[j] = make([]float64, inputDim)
+		for i, x := range v {
+			centered[j][i] = float64(x) - mean[i]
+		}
+	}
+
+	// subspace iteration: basis <- orthonormalise(X^T X basis)
+	basis := randomBasis(dim, inputDim, rand.New(rand.NewSource(1)))
+	projected := make([]float64, dim)
+	for it := 0; it < pcaIterations; it++ {
+		next := make([][]float64, dim)
+		for k := range next {
+			next[k] = make([]float64, inputDim)
+		}
+		for _, x := range centered {
+			for k, b := range basis {
+				projected[k] = dot(b, x)
+			}
+			for k := range next {
+				for i, xi := range x {
+					next[k][i] += projected[k] * xi
+				}
+			}
+		}
+		orthonormalise(next)
+		basis = next
+	}
+
+	t := &framework.Transform{
+		Kind:      TransformPCA,
+		InputDim:  uint64(inputDim),
+		OutputDim: uint64(dim),
+		Mean:      make([]float32, inputDim),
+	}
+	for i, m := range mean {
+		t.Mean[i] = float32(m)
+	}
+	t.Matrix = flatten(basis)
+	return t, nil
+}
+
+// TrainRandomProjection returns a transform onto dim random orthonormal
+// directions, sample only determines the input dimension
+func TrainRandomProjection(sample [][]float32, dim int, seed int64) (*framework.Transform, error) {
+	inputDim, err := checkSample(sample, dim)
+	if err != nil {
+		return nil, err
+	}
+	basis := randomBasis(dim, inputDim, rand.New(rand.NewSource(seed)))
+	return &framework.Transform{
+		Kind:      TransformRandomProjection,
+		InputDim:  uint64(inputDim),
+		OutputDim: uint64(dim),
+		Matrix:    flatten(basis),
+	}, nil
+}
+
+func checkSample(sample [][]float32, dim int) (int, error) {
+	if len(sample) == 0 {
+		return 0, errors.New("hnsw: empty sample")
+	}
+	inputDim := len(sample[0])
+	for _, v := range sample {
+		if len(v) != inputDim {
+			return 0, errors.New("hnsw: sample vectors differ in dimension")
+		}
+	}
+	if dim <= 0 || dim > inputDim {
+		return 0, fmt.Errorf("hnsw: can't reduce dimension %d to %d", inputDim, dim)
+	}
+	return inputDim, nil
+}
+
+// randomBasis returns n orthonormal vectors of dimension dim
+func randomBasis(n, dim int, r *rand.Rand) [][]float64 {
+	basis := make([][]float64, n)
+	for k := range basis {
+		basis[k] = make([]float64, dim)
+		for i := range basis[k] {
+			basis[k][i] = r.NormFloat64()
+		}
+	}
+	orthonormalise(basis)
+	return basis
+}
+
+// orthonormalise runs modified Gram-Schmidt on the vectors in place
+func orthonormalise(vectors [][]float64) {
+	for k, v := range vectors {
+		for _, u := range vectors[:k] {
+			d := dot(u, v)
+			for i := range v {
+				v[i] -= d * u[i]
+			}
+		}
+		norm := math.Sqrt(dot(v, v))
+		if norm == 0 {
+			// degenerate sample, any unit vector will do
+			v[k%len(v)] = 1
+			continue
+		}
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func flatten(rows [][]float64) []float32 {
+	m := make([]float32, 0, len(rows)*len(rows[0]))
+	for _, r := range rows {
+		for _, x := range r {
+			m = append(m, float32(x))
+		}
+	}
+	return m
+}
+
+// applyTransform returns the projection of q, q itself if t is nil
+func applyTransform(t *framework.Transform, q framework.Point) (framework.Point, error) {
+	if t == nil {
+		return q, nil
+	}
+	if uint64(len(q)) != t.InputDim {
+		return nil, fmt.Errorf("%w: point has dimension %d, the transform expects %d", ErrDimensionMismatch, len(q), t.InputDim)
+	}
+	out := make(framework.Point, t.OutputDim)
+	in := int(t.InputDim)
+	for k := range out {
+		row := t.Matrix[k*in : (k+1)*in]
+		var s float32
+		for i, x := range q {
+			if t.Mean != nil {
+				x -= t.Mean[i]
+			}
+			s += row[i] * x
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
+// NewWithTransform is like New but projects every point added to or
+// searched in the index with t, including first. The transform is saved
+// with the index.
+func NewWithTransform(M uint64, efConstruction uint64, first framework.Point, t *framework.Transform) (*Hnsw, error) {
+	p, err := applyTransform(t, first)
+	if err != nil {
+		return nil, err
+	}
+	h := New(M, efConstruction, p)
+	h.Transform = t
+	return h, nil
+}
@@ -0,0 +1,119 @@
+package hnsw
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/jnmly/go-hnsw/framework"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrainPCA(t *testing.T) {
+	// points in a 3 dimensional subspace of a 16 dimensional space
+	r := rand.New(rand.NewSource(3))
+	axes, _ := TrainRandomProjection([][]float32{make([]float32, 16)}, 3, 7)
+	sample := make([][]float32, 200)
+	for j := range sample {
+		sample[j] = make([]float32, 16)
+		for k := 0; k < 3; k++ {
+			c := float32(r.NormFloat64() * float64(3-k))
+			for i := range sample[j] {
+				sample[j][i] += c * axes.Matrix[16*k+i]
+			}
+		}
+		sample[j][0] += 5
+	}
+
+	tr, err := TrainPCA(sample, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), tr.InputDim)
+	assert.Equal(t, uint64(3), tr.OutputDim)
+
+	// distances within the subspace are preserved
+	for j := 1; j < len(sample); j++ {
+		a, err := applyTransform(tr, sample[j-1])
+		assert.NoError(t, err)
+		b, _ := applyTransform(tr, sample[j])
+		var orig, reduced float64
+		for i := range sample[j] {
+			orig += math.Pow(float64(sample[j][i]-sample[j-1][i]), 2)
+		}
+		for i := range a {
+			reduced += math.Pow(float64(a[i]-b[i]), 2)
+		}
+		assert.InDelta(t, orig, reduced, 1e-3*(1+orig))
+	}
+
+	_, err = TrainPCA(sample, 17)
+	assert.Error(t, err)
+	_, err = applyTransform(tr, make([]float32, 15))
+	assert.Error(t, err)
+}
+
+func TestTransform(t *testing.T) {
+	q, vecs := getTestdata(t)
+	vecs = vecs[:300]
+
+	for _, kind := range []int{TransformPCA, TransformRandomProjection} {
+		var tr *framework.Transform
+		var err error
+		if kind == TransformPCA {
+			tr, err = TrainPCA(vecs, 32)
+		} else {
+			tr, err = TrainRandomProjection(vecs, 32, 1)
+		}
+		assert.NoError(t, err)
+
+		// the rows of the projection are orthonormal
+		for k := 0; k < 32; k++ {
+			for l := 0; l < 32; l++ {
+				var d float32
+				for i := 0; i < dimsize; i++ {
+					d += tr.Matrix[k*dimsize+i] * tr.Matrix[l*dimsize+i]
+				}
+				if k == l {
+					assert.InDelta(t, 1, d, 1e-4)
+				} else {
+					assert.InDelta(t, 0, d, 1e-4)
+				}
+			}
+		}
+
+		h, err := NewWithTransform(16, 100, make([]float32, dimsize), tr)
+		assert.NoError(t, err)
+		for _, v := range vecs {
+			h.Add(v)
+		}
+		assert.Equal(t, 32, len(h.Nodes[1].P))
+		_, err = h.AddContext(context.Background(), make([]float32, 32))
+		assert.Error(t, err)
+		_, err = h.SearchContext(context.Background(), make([]float32, 32), 100, 10)
+		assert.Error(t, err)
+
+		res := h.Search(q, 100, 10)
+		assert.Equal(t, uint64(10), res.Len())
+		if kind == TransformPCA {
+			// the projected points are found again
+			found := 0
+			for i, v := range vecs {
+				if h.Search(v, 100, 1).Pop().Node == uint64(i+1) {
+					found++
+				}
+			}
+			assert.True(t, found > 290, "found %d", found)
+		}
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, h.Save(buf))
+		g, err := Load(buf)
+		assert.NoError(t, err)
+		assert.True(t, tr.Equal(g.Transform))
+		a, b := h.Search(q, 100, 10), g.Search(q, 100, 10)
+		for a.Len() > 0 {
+			assert.Equal(t, a.Pop().Node, b.Pop().Node)
+		}
+	}
+}
@@ -2,40 +2,130 @@ package hnsw
 
 import (
 	"fmt"
+	"sync/atomic"
 )
 
-func (h *Hnsw) Stats() string {
+// IndexStats describes the shape of an index, see StatsStruct
+type IndexStats struct {
+	M              uint64
+	EfConstruction uint64
+	DelaunayType   uint64
+	Nodes          int
+	MaxLayer       uint64
+	// Levels is indexed by level
+	Levels []LevelStats
+	// MemoryData and MemoryIndex estimate the bytes used by the points and
+	// by the friend lists
+	MemoryData  uint64
+	MemoryIndex uint64
+	// Orphans counts the nodes besides the enterpoint no other node links
+	// to, Unreachable the nodes a search from the enterpoint can't reach
+	Orphans     int
+	Unreachable int
+}
+
+// LevelStats describes one level of an index
+type LevelStats struct {
+	// Nodes is the number of nodes whose top level is this level, CountLevel
+	// the number maintained by the index, both should be equal
+	Nodes      uint64
+	CountLevel uint64
+	// degree of the nodes linked on this level
+	AvgDegree float64
+	MinDegree int
+	MaxDegree int
+}
+
+// StatsStruct returns the statistics printed by Stats
+func (h *Hnsw) StatsStruct() IndexStats {
 	h.RLock()
 	defer h.RUnlock()
 
-	s := "HNSW Index\n"
-	s = s + fmt.Sprintf("M: %v, efConstruction: %v\n", h.M, h.EfConstruction)
-	s = s + fmt.Sprintf("DelaunayType: %v\n", h.DelaunayType)
-	s = s + fmt.Sprintf("Number of nodes: %v\n", len(h.Nodes))
-	s = s + fmt.Sprintf("Max layer: %v\n", h.MaxLayer)
-	memoryUseData := 0
-	memoryUseIndex := uint64(0)
-	levCount := make([]uint64, h.MaxLayer+1)
-	conns := make([]uint64, h.MaxLayer+1)
-	connsC := make([]uint64, h.MaxLayer+1)
-	for i := range h.Nodes {
-		levCount[h.Nodes[i].Level]++
-		for j := uint64(0); j <= h.Nodes[i].Level; j++ {
-			if h.Nodes[i].FriendLevelCount() > j {
-				l := len(h.Nodes[i].Friends[j].Nodes)
-				conns[j] += uint64(l)
+	nodes := h.nodeList()
+	maxLayer := atomic.LoadUint64(&h.MaxLayer)
+	enterpoint := atomic.LoadUint64(&h.Enterpoint)
+
+	stats := IndexStats{
+		M:              h.M,
+		EfConstruction: h.EfConstruction,
+		DelaunayType:   h.DelaunayType,
+		Nodes:          len(nodes),
+		MaxLayer:       maxLayer,
+		Levels:         make([]LevelStats, maxLayer+1),
+	}
+
+	h.nodesLock.RLock()
+	for i := range stats.Levels {
+		stats.Levels[i].CountLevel = h.CountLevel[uint64(i)]
+	}
+	h.nodesLock.RUnlock()
+
+	conns := make([]uint64, maxLayer+1)
+	connsC := make([]uint64, maxLayer+1)
+	incoming := make(map[uint64]bool, len(nodes))
+	for _, n := range nodes {
+		if n.Level > maxLayer {
+			// added after MaxLayer was read
+			continue
+		}
+		stats.Levels[n.Level].Nodes++
+		l := h.nodeLock(n.Id)
+		l.RLock()
+		for j := uint64(0); j <= n.Level; j++ {
+			if l, ok := n.Friends[j]; ok {
+				friends := l.Nodes
+				for _, f := range friends {
+					incoming[f] = true
+				}
+				ls := &stats.Levels[j]
+				if connsC[j] == 0 || len(friends) < ls.MinDegree {
+					ls.MinDegree = len(friends)
+				}
+				if len(friends) > ls.MaxDegree {
+					ls.MaxDegree = len(friends)
+				}
+				conns[j] += uint64(len(friends))
 				connsC[j]++
 			}
 		}
-		memoryUseData += len(h.Nodes[i].P) * 4
-		memoryUseIndex += h.Nodes[i].Level*h.M*4 + h.M0*4
+		l.RUnlock()
+		stats.MemoryData += uint64(len(n.P) * 4)
+		stats.MemoryIndex += n.Level*h.M*4 + h.M0*4
 	}
-	for i := range levCount {
-		avg := conns[i] / max(1, connsC[i])
-		s = s + fmt.Sprintf("Level %v: %v (%d) nodes, average number of connections %v\n", i, levCount[uint64(i)], h.CountLevel[uint64(i)], avg)
+	for i := range stats.Levels {
+		if connsC[i] > 0 {
+			stats.Levels[i].AvgDegree = float64(conns[i]) / float64(connsC[i])
+		}
+	}
+
+	reachable := h.reachablePerLevel()[0]
+	for _, n := range nodes {
+		if n.Id != enterpoint && !incoming[n.Id] {
+			stats.Orphans++
+		}
+		if !reachable[n.Id] {
+			stats.Unreachable++
+		}
+	}
+
+	return stats
+}
+
+func (h *Hnsw) Stats() string {
+	stats := h.StatsStruct()
+
+	s := "HNSW Index\n"
+	s = s + fmt.Sprintf("M: %v, efConstruction: %v\n", stats.M, stats.EfConstruction)
+	s = s + fmt.Sprintf("DelaunayType: %v\n", stats.DelaunayType)
+	s = s + fmt.Sprintf("Number of nodes: %v\n", stats.Nodes)
+	s = s + fmt.Sprintf("Max layer: %v\n", stats.MaxLayer)
+	for i, l := range stats.Levels {
+		s = s + fmt.Sprintf("Level %v: %v (%d) nodes, average number of connections %v\n", i, l.Nodes, l.CountLevel, uint64(l.AvgDegree))
 	}
-	s = s + fmt.Sprintf("Memory use for data: %v (%v bytes / point)\n", memoryUseData, memoryUseData/len(h.Nodes))
-	s = s + fmt.Sprintf("Memory use for index: %v (avg %v bytes / point)\n", memoryUseIndex, memoryUseIndex/uint64(len(h.Nodes)))
+	nodes := max(1, uint64(stats.Nodes))
+	s = s + fmt.Sprintf("Memory use for data: %v (%v bytes / point)\n", stats.MemoryData, stats.MemoryData/nodes)
+	s = s + fmt.Sprintf("Memory use for index: %v (avg %v bytes / point)\n", stats.MemoryIndex, stats.MemoryIndex/nodes)
+	s = s + fmt.Sprintf("Orphans: %v, unreachable: %v\n", stats.Orphans, stats.Unreachable)
 	return s
 }
 
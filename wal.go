@@ -0,0 +1,363 @@
+package hnsw
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/jnmly/go-hnsw/framework"
+)
+
+const (
+	walOpAdd    = 1
+	walOpRemove = 2
+	walOpTags   = 3
+	// an addition together with the tags and document of the node
+	walOpAddTagged = 4
+
+	// op, node id and length of the payload in 4 byte words
+	walHeaderSize = 1 + 8 + 4
+
+	// upper bound for the dimension, protects against huge allocations on a corrupt header
+	walMaxDim = 1 << 20
+)
+
+var errWALCorrupt = errors.New("hnsw: corrupt write-ahead log record")
+
+// WAL is an append-only log of the mutations applied to an index since its
+// last checkpoint. Every record holds the operation, the node id, the point
+// for additions, the tags for tag changes or the document key of a node,
+// followed by a CRC32 of the record. Additions with tags or a document are
+// a single record, so replay restores them completely or not at all.
+type WAL struct {
+	sync.Mutex
+	filename string
+	f        *os.File
+	w        *bufio.Writer
+
+	// SyncWrites makes every append wait for fsync, otherwise records are
+	// only flushed to the operating system
+	SyncWrites bool
+}
+
+// OpenWAL opens or creates a write-ahead log, new records are appended at the end
+func OpenWAL(filename string) (*WAL, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &WAL{filename: filename, f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (w *WAL) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+func (w *WAL) logAdd(id uint64, p framework.Point, tags []uint64, document uint64) error {
+	if len(tags) == 0 && document == 0 {
+		payload := make([]byte, 4*len(p))
+		putPoint(payload, p)
+		return w.append(walOpAdd, id, payload)
+	}
+	// document, number of tags, tags and point
+	payload := make([]byte, 8+4+8*len(tags)+4*len(p))
+	binary.LittleEndian.PutUint64(payload, document)
+	binary.LittleEndian.PutUint32(payload[8:], uint32(len(tags)))
+	for i, t := range tags {
+		binary.LittleEndian.PutUint64(payload[12+8*i:], t)
+	}
+	putPoint(payload[12+8*len(tags):], p)
+	return w.append(walOpAddTagged, id, payload)
+}
+
+func putPoint(buf []byte, p framework.Point) {
+	for i, f := range p {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+}
+
+func readPoint(buf []byte) framework.Point {
+	p := make(framework.Point, len(buf)/4)
+	for i := range p {
+		p[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return p
+}
+
+func (w *WAL) logRemove(id uint64) error {
+	return w.append(walOpRemove, id, nil)
+}
+
+func (w *WAL) logTags(id uint64, tags []uint64) error {
+	payload := make([]byte, 8*len(tags))
+	for i, t := range tags {
+		binary.LittleEndian.PutUint64(payload[8*i:], t)
+	}
+	return w.append(walOpTags, id, payload)
+}
+
+// append writes a record, the length of payload must be a multiple of 4
+func (w *WAL) append(op byte, id uint64, payload []byte) error {
+	w.Lock()
+	defer w.Unlock()
+
+	buf := make([]byte, walHeaderSize+len(payload)+4)
+	buf[0] = op
+	binary.LittleEndian.PutUint64(buf[1:], id)
+	binary.LittleEndian.PutUint32(buf[9:], uint32(len(payload)/4))
+	copy(buf[walHeaderSize:], payload)
+	crc := crc32.ChecksumIEEE(buf[:len(buf)-4])
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], crc)
+
+	if _, err := w.w.Write(buf); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.SyncWrites {
+		return w.f.Sync()
+	}
+	return nil
+}
+
+// size returns the length of the log including all appended records
+func (w *WAL) size() (int64, error) {
+	w.Lock()
+	defer w.Unlock()
+	if err := w.w.Flush(); err != nil {
+		return 0, err
+	}
+	return w.f.Seek(0, io.SeekCurrent)
+}
+
+// discard removes the first offset bytes of the log after they have been
+// made durable by a checkpoint. The remaining records are written to a new
+// file which atomically replaces the log.
+func (w *WAL) discard(offset int64) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	end, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if offset == end {
+		return w.truncateAt(0)
+	}
+
+	tail := make([]byte, end-offset)
+	if _, err := w.f.ReadAt(tail, offset); err != nil {
+		return err
+	}
+
+	tmp := w.filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(tail); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := os.Rename(tmp, w.filename); err != nil {
+		f.Close()
+		return err
+	}
+
+	w.f.Close()
+	w.f = f
+	w.w.Reset(f)
+	return nil
+}
+
+func (w *WAL) truncateAt(offset int64) error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if err := w.f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	w.w.Reset(w.f)
+	return w.f.Sync()
+}
+
+func readWALRecord(r *bufio.Reader) (op byte, id uint64, payload []byte, n int64, err error) {
+	header := make([]byte, walHeaderSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	op = header[0]
+	id = binary.LittleEndian.Uint64(header[1:])
+	dim := binary.LittleEndian.Uint32(header[9:])
+	if op < walOpAdd || op > walOpAddTagged || dim > walMaxDim {
+		err = errWALCorrupt
+		return
+	}
+
+	body := make([]byte, 4*int(dim)+4)
+	if _, err = io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	crc := crc32.ChecksumIEEE(header)
+	crc = crc32.Update(crc, crc32.IEEETable, body[:len(body)-4])
+	if crc != binary.LittleEndian.Uint32(body[len(body)-4:]) {
+		err = errWALCorrupt
+		return
+	}
+
+	payload = body[:len(body)-4]
+	n = int64(len(header) + len(body))
+	return
+}
+
+// replay applies every record of the log to h. Records already contained in
+// the snapshot h was loaded from are skipped. A torn or corrupt record ends
+// the log, it and everything after it is discarded.
+func (w *WAL) replay(h *Hnsw) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(w.f)
+
+	h.Lock()
+	defer h.Unlock()
+
+	// concurrent inserts may have logged their records out of id order
+	sequence := h.Sequence
+	offset := int64(0)
+	for {
+		op, id, payload, n, err := readWALRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || err == errWALCorrupt {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case walOpAdd:
+			if id >= sequence {
+				h.add(readPoint(payload), id, nil, 0)
+			}
+		case walOpAddTagged:
+			if id >= sequence && len(payload) >= 12 {
+				document := binary.LittleEndian.Uint64(payload)
+				count := int(binary.LittleEndian.Uint32(payload[8:]))
+				if len(payload) < 12+8*count {
+					break
+				}
+				tags := make([]uint64, count)
+				for i := range tags {
+					tags[i] = binary.LittleEndian.Uint64(payload[12+8*i:])
+				}
+				h.add(readPoint(payload[12+8*count:]), id, tags, document)
+			}
+		case walOpRemove:
+			if _, ok := h.Nodes[id]; ok {
+				h.remove(id)
+			}
+		case walOpTags:
+			if _, ok := h.Nodes[id]; ok {
+				tags := make([]uint64, len(payload)/8)
+				for i := range tags {
+					tags[i] = binary.LittleEndian.Uint64(payload[8*i:])
+				}
+				h.setTags(id, tags)
+			}
+		}
+		offset += n
+	}
+
+	return w.truncateAt(offset)
+}
+
+// SetWAL attaches a write-ahead log to the index. Every following Add,
+// Remove and SetTags is appended to the log before it is applied.
+func (h *Hnsw) SetWAL(w *WAL) {
+	h.Lock()
+	h.wal = w
+	h.Unlock()
+}
+
+// Open loads the snapshot written by Checkpoint, replays the write-ahead log
+// on top of it and attaches the log to the returned index
+func Open(snapshot string, walFile string) (*Hnsw, error) {
+	h, err := loadSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := OpenWAL(walFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.replay(h); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	h.wal = w
+	return h, nil
+}
+
+// Checkpoint writes a snapshot of the index to filename and then removes
+// the records it contains from the attached write-ahead log. Writers are
+// only blocked while the snapshot is taken.
+func (h *Hnsw) Checkpoint(filename string) error {
+	h.Lock()
+	s := h.snapshot()
+	wal := h.wal
+	var offset int64
+	if wal != nil {
+		var err error
+		if offset, err = wal.size(); err != nil {
+			h.Unlock()
+			s.Close()
+			return err
+		}
+	}
+	h.Unlock()
+	defer s.Close()
+
+	s.sortIDs()
+	if err := writeSnapshot(s, filename); err != nil {
+		return err
+	}
+	if wal != nil {
+		return wal.discard(offset)
+	}
+	return nil
+}
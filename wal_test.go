@@ -0,0 +1,164 @@
+package hnsw
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWALReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "index")
+	walFile := filepath.Join(dir, "wal")
+
+	_, vecs := getTestdata(t)
+	vecs = vecs[:300]
+
+	h := newSmallHnsw()
+	w, err := OpenWAL(walFile)
+	assert.NoError(t, err)
+	h.SetWAL(w)
+
+	for _, v := range vecs[:100] {
+		h.Add(v)
+	}
+	assert.NoError(t, h.Checkpoint(snapshot))
+
+	for _, v := range vecs[100:] {
+		h.Add(v)
+	}
+	h.Remove(50)
+	h.Remove(150)
+	assert.NoError(t, w.Close())
+
+	// simulate a crash, nothing but the snapshot and the log survive
+	g, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, len(h.Nodes), len(g.Nodes))
+	assert.Equal(t, h.Sequence, g.Sequence)
+	for id, n := range h.Nodes {
+		assert.Equal(t, n.P, g.Nodes[id].P)
+	}
+	assert.Nil(t, g.Nodes[50])
+	assert.Nil(t, g.Nodes[150])
+
+	// the reopened index keeps logging
+	id := g.Add(vecs[0])
+	assert.NoError(t, g.Checkpoint(snapshot))
+	info, err := os.Stat(walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+	assert.NoError(t, g.wal.Close())
+
+	k, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, vecs[0], k.Nodes[id].P)
+	assert.NoError(t, k.wal.Close())
+}
+
+func TestWALTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "index")
+	walFile := filepath.Join(dir, "wal")
+
+	_, vecs := getTestdata(t)
+
+	h := newSmallHnsw()
+	assert.NoError(t, h.Checkpoint(snapshot))
+	w, err := OpenWAL(walFile)
+	assert.NoError(t, err)
+	h.SetWAL(w)
+	for _, v := range vecs[:10] {
+		h.Add(v)
+	}
+	assert.NoError(t, w.Close())
+
+	// cut the last record in half
+	info, err := os.Stat(walFile)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(walFile, info.Size()-100))
+
+	g, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, len(g.Nodes))
+	assert.Nil(t, g.Nodes[9])
+
+	// the torn record has been cut off, new records follow the last good one
+	g.Add(vecs[9])
+	assert.NoError(t, g.wal.Close())
+	k, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(k.Nodes))
+	assert.NoError(t, k.wal.Close())
+}
+
+func TestWALReplayTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "index")
+	walFile := filepath.Join(dir, "wal")
+
+	_, vecs := getTestdata(t)
+
+	h := newSmallHnsw()
+	w, err := OpenWAL(walFile)
+	assert.NoError(t, err)
+	h.SetWAL(w)
+
+	a := h.AddWithTags(vecs[0], []uint64{1, 2})
+	assert.NoError(t, h.Checkpoint(snapshot))
+	b := h.AddWithTags(vecs[1], []uint64{1 << 40})
+	h.SetTags(a, []uint64{3})
+	docs, err := h.AddDocument(7, vecs[2:4])
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	g, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3}, g.Tags(a))
+	assert.Equal(t, []uint64{1 << 40}, g.Tags(b))
+	assert.ElementsMatch(t, docs, g.DocumentNodes(7))
+	assert.Equal(t, uint64(7), g.Document(docs[0]))
+}
+
+func TestWALTornTaggedAdd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hnsw")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "index")
+	walFile := filepath.Join(dir, "wal")
+
+	_, vecs := getTestdata(t)
+
+	h := newSmallHnsw()
+	assert.NoError(t, h.Checkpoint(snapshot))
+	w, err := OpenWAL(walFile)
+	assert.NoError(t, err)
+	h.SetWAL(w)
+	a := h.AddWithTags(vecs[0], []uint64{1})
+	info, err := os.Stat(walFile)
+	assert.NoError(t, err)
+	h.AddWithTags(vecs[1], []uint64{1})
+	assert.NoError(t, w.Close())
+
+	// cut where the point ends and the tags used to be logged separately
+	assert.NoError(t, os.Truncate(walFile, info.Size()+walHeaderSize+4*dimsize+4))
+
+	g, err := Open(snapshot, walFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(g.Nodes))
+	assert.Equal(t, []uint64{1}, g.Tags(a))
+	res, err := g.SearchWithOptions(context.Background(), vecs[1], 100, 10, SearchOptions{Tags: Tag(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), res.Len())
+	assert.NoError(t, g.wal.Close())
+}